@@ -0,0 +1,140 @@
+// MCP Go Starter - Transport Bridge
+//
+// This command connects clients and servers that speak different
+// transports. It has two modes, named after the transport it serves locally:
+//
+//	stdio: expose a remote streamable-HTTP server as a local stdio server
+//	http:  expose a stdio server command as a streamable-HTTP server
+//
+// Requests, notifications, progress, sampling and elicitation are forwarded
+// in both directions.
+//
+// Usage:
+//
+//	go run ./cmd/bridge stdio http://localhost:3000/mcp
+//	go run ./cmd/bridge http go run ./cmd/stdio
+//	PORT=8080 go run ./cmd/bridge http ./bin/stdio
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/bridge"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionTimeout closes idle HTTP sessions, and with them the stdio
+// subprocess spawned for each one.
+const sessionTimeout = 30 * time.Minute
+
+func main() {
+	if err := run(); err != nil {
+		log.Printf("Bridge error: %v", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle shutdown signals
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	log.SetOutput(os.Stderr) // Don't interfere with stdio protocol
+
+	args := os.Args[1:]
+	if len(args) < 2 {
+		return errors.New("usage: bridge stdio <url> | bridge http <command> [args...]")
+	}
+	switch args[0] {
+	case "stdio":
+		return runStdio(ctx, args[1])
+	case "http":
+		return runHTTP(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown mode %q (want stdio or http)", args[0])
+	}
+}
+
+// runStdio serves a remote streamable-HTTP server on stdin/stdout.
+func runStdio(ctx context.Context, url string) error {
+	proxy := bridge.New(&mcp.StreamableClientTransport{Endpoint: url})
+	defer proxy.Close()
+
+	log.Printf("Bridging stdio to %s", url)
+
+	session, err := proxy.Server().Connect(ctx, &mcp.StdioTransport{}, nil)
+	if err != nil {
+		return err
+	}
+
+	// Exit when either side goes away.
+	done := make(chan struct{}, 2)
+	go func() { _ = session.Wait(); done <- struct{}{} }()
+	go func() {
+		if err := proxy.Wait(); err != nil {
+			log.Printf("Upstream %s: %v", url, err)
+		}
+		done <- struct{}{}
+	}()
+	select {
+	case <-ctx.Done():
+	case <-done:
+	}
+	log.Println("Bridge shutting down")
+	return session.Close()
+}
+
+// runHTTP serves a stdio server command over streamable HTTP. Every HTTP
+// session gets its own subprocess, so server-initiated requests such as
+// sampling always reach the client that caused them.
+func runHTTP(ctx context.Context, command []string) error {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "3000"
+	}
+
+	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stderr = os.Stderr
+		return bridge.New(&mcp.CommandTransport{Command: cmd}).Server()
+	}, &mcp.StreamableHTTPOptions{SessionTimeout: sessionTimeout})
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", handler)
+
+	addr := fmt.Sprintf(":%s", port)
+	log.Printf("Bridging http://localhost%s/mcp to %v", addr, command)
+
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	// Graceful shutdown
+	go func() {
+		<-ctx.Done()
+		log.Println("Shutting down bridge...")
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// MCP Go Starter - Capability Manifest
+//
+// This command builds the server with every toolset enabled, lists every
+// tool, resource, resource template and prompt exactly as a client would
+// see them, and prints the result as JSON or Markdown. It can also check or
+// regenerate server.json so registry metadata stays in sync with what the
+// server registers.
+//
+// Usage:
+//
+//	go run ./cmd/describe                      # JSON manifest
+//	go run ./cmd/describe -format markdown     # Markdown reference
+//	go run ./cmd/describe -check server.json   # exit non-zero if out of sync
+//	go run ./cmd/describe -write server.json   # regenerate metadata
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/manifest"
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/server"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Printf("Describe failed: %v", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	format := flag.String("format", "json", "output format: json or markdown")
+	check := flag.String("check", "", "verify that this server.json matches the server")
+	write := flag.String("write", "", "update this server.json to match the server")
+	flag.Parse()
+
+	// Describe every tool, not only those enabled by default.
+	server.SetDefaultToolsets(server.AllToolsets())
+	srv := server.NewServer()
+
+	m, err := manifest.Build(context.Background(), srv)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *check != "":
+		data, err := os.ReadFile(*check)
+		if err != nil {
+			return err
+		}
+		problems, err := manifest.CheckServerJSON(data, m)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *check, err)
+		}
+		for _, p := range problems {
+			fmt.Printf("%s: %s\n", *check, p)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%s is out of date; run: go run ./cmd/describe -write %s", *check, *check)
+		}
+		fmt.Printf("%s is up to date\n", *check)
+		return nil
+
+	case *write != "":
+		data, err := os.ReadFile(*write)
+		if err != nil {
+			return err
+		}
+		updated, err := manifest.UpdateServerJSON(data, m)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *write, err)
+		}
+		return os.WriteFile(*write, updated, 0o644)
+	}
+
+	switch *format {
+	case "json":
+		data, err := m.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "markdown", "md":
+		fmt.Print(m.Markdown())
+	default:
+		return fmt.Errorf("unknown format %q (want json or markdown)", *format)
+	}
+	return nil
+}
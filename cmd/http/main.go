@@ -7,6 +7,12 @@
 //
 //	go run ./cmd/http
 //	PORT=8080 go run ./cmd/http
+//	MCP_GATEWAY_CONFIG=gateway.json go run ./cmd/http
+//
+// Besides /mcp and /health, it serves /elicit/, the pages get_feedback sends
+// users to for URL-mode elicitation. Set MCP_PUBLIC_URL to the address users
+// reach the server at (e.g. "https://mcp.example.com") when it isn't
+// http://localhost:$PORT, such as behind a proxy.
 //
 // Documentation: https://modelcontextprotocol.io/docs/develop/transports#streamable-http
 package main
@@ -18,8 +24,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/elicit"
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/gateway"
 	"github.com/SamMorrowDrums/mcp-go-starter/internal/server"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -48,10 +57,34 @@ func run() error {
 		port = "3000"
 	}
 
+	gatewayConfig, err := gateway.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	if err := server.ConfigureFromEnv(); err != nil {
+		return err
+	}
+
+	publicURL := strings.TrimSuffix(os.Getenv("MCP_PUBLIC_URL"), "/")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+
+	// get_feedback's URL elicitation opens a form hosted here, so the
+	// answer comes back to the tool that asked.
+	forms := elicit.NewURLForms(publicURL + "/elicit/")
+	server.SetURLForms(forms)
+
 	// Create HTTP handler for MCP
-	handler := mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server {
+	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
 		srv := server.NewServer()
-		server.SetGlobalServer(srv)
+
+		// In gateway mode, each session gets its own upstream connections so
+		// that sampling and elicitation are routed back to the right client.
+		// They are closed when the session ends.
+		if gatewayConfig != nil {
+			gateway.Attach(srv, gatewayConfig)
+		}
 		return srv
 	}, nil)
 
@@ -59,6 +92,7 @@ func run() error {
 	mux := http.NewServeMux()
 	mux.Handle("/mcp", handler)
 	mux.HandleFunc("/health", healthHandler)
+	mux.Handle("/elicit/", http.StripPrefix("/elicit", forms))
 
 	addr := fmt.Sprintf(":%s", port)
 
@@ -66,6 +100,7 @@ func run() error {
 	log.Printf("MCP Go Starter running on http://localhost%s", addr)
 	log.Printf("  MCP endpoint: http://localhost%s/mcp", addr)
 	log.Printf("  Health check: http://localhost%s/health", addr)
+	log.Printf("  Elicitation forms: %s/elicit/", publicURL)
 	log.Println("Press Ctrl+C to exit")
 
 	httpServer := &http.Server{
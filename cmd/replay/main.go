@@ -0,0 +1,81 @@
+// MCP Go Starter - Session Replay
+//
+// This command feeds a recorded stdio session back into a fresh server and
+// reports any differences between the recorded and actual responses. It
+// exits non-zero when the replay diverges, so recordings can be used as
+// regression tests in CI.
+//
+// Usage:
+//
+//	MCP_RECORD_FILE=session.jsonl go run ./cmd/stdio   # record
+//	go run ./cmd/replay session.jsonl                  # replay
+//	go run ./cmd/replay -ignore temperature,humidity session.jsonl
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/recording"
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/server"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Printf("Replay failed: %v", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	timeout := flag.Duration("timeout", 5*time.Second, "how long to wait for each expected message, beyond the time it took when recorded")
+	settle := flag.Duration("settle", 500*time.Millisecond, "how long to listen for unexpected messages after the last entry")
+	ignore := flag.String("ignore", "", "comma-separated JSON keys to ignore when comparing")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		return fmt.Errorf("usage: replay [-timeout d] [-settle d] [-ignore keys] <recording.jsonl>")
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := recording.ReadEntries(f)
+	if err != nil {
+		return err
+	}
+
+	opts := &recording.ReplayOptions{Timeout: *timeout, Settle: *settle}
+	if *ignore != "" {
+		opts.Ignore = strings.Split(*ignore, ",")
+	}
+
+	// Replays must see the same tools, and gate the same calls, as the
+	// recorded session. A fixture provider (WEATHER_PROVIDER=fixture) makes
+	// get_weather deterministic, so its results need not be ignored.
+	if err := server.ConfigureFromEnv(); err != nil {
+		return err
+	}
+
+	srv := server.NewServer()
+
+	diffs, err := recording.Replay(context.Background(), srv, entries, opts)
+	if err != nil {
+		return err
+	}
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("%d difference(s) in %d entries", len(diffs), len(entries))
+	}
+	fmt.Printf("Replayed %d entries with no differences\n", len(entries))
+	return nil
+}
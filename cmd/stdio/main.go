@@ -6,17 +6,33 @@
 // Usage:
 //
 //	go run ./cmd/stdio
+//	MCP_RECORD_FILE=session.jsonl go run ./cmd/stdio
+//
+// Set MCP_RECORD_FILE to record every JSON-RPC message to a JSONL file,
+// which can later be replayed with ./cmd/replay.
+//
+// The server exits when the client closes stdin. Set MCP_WATCH_PARENT=true
+// to also exit when the launching process dies, and MCP_IDLE_TIMEOUT (e.g.
+// "10m") to exit after a period with no client activity.
+//
+// Set MCP_GATEWAY_CONFIG to a gateway config file to also re-expose the
+// tools, resources and prompts of other MCP servers (see internal/gateway).
 //
 // Documentation: https://modelcontextprotocol.io/docs/develop/transports#stdio
 package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/gateway"
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/recording"
 	"github.com/SamMorrowDrums/mcp-go-starter/internal/server"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -29,34 +45,94 @@ func main() {
 }
 
 func run() error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
 
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigChan
-		cancel()
+		cancel(errSignal)
 	}()
 
+	watchParentEnabled, idleTimeout, err := supervisionConfig()
+	if err != nil {
+		return err
+	}
+	gatewayConfig, err := gateway.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	if err := server.ConfigureFromEnv(); err != nil {
+		return err
+	}
+
 	// Create the MCP server
 	srv := server.NewServer()
-	server.SetGlobalServer(srv)
+
+	// In gateway mode, mirror the configured upstream servers
+	if gatewayConfig != nil {
+		defer gateway.Attach(srv, gatewayConfig).Close()
+	}
+
+	idle := newIdleTracker(time.Now)
+	if idleTimeout > 0 {
+		srv.AddReceivingMiddleware(idle.middleware)
+	}
 
 	// Create stdio transport
-	transport := &mcp.StdioTransport{}
+	var transport mcp.Transport = &mcp.StdioTransport{}
 
 	// Connect and run
 	log.SetOutput(os.Stderr) // Don't interfere with stdio protocol
 	log.Println("MCP Go Starter running on stdio")
 
-	if _, err := srv.Connect(ctx, transport, nil); err != nil {
+	// Optionally record all traffic for later replay
+	if path := os.Getenv("MCP_RECORD_FILE"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		transport = &recording.Transport{Transport: transport, Writer: f}
+		log.Printf("Recording session to %s", path)
+	}
+
+	session, err := srv.Connect(ctx, transport, nil)
+	if err != nil {
 		return err
 	}
 
-	// Wait for context cancellation
+	// Supervise the session: any of these ends the process.
+	go func() {
+		_ = session.Wait()
+		cancel(errDisconnected)
+	}()
+	if watchParentEnabled {
+		go watchParent(ctx, cancel, time.Second)
+	}
+	if idleTimeout > 0 {
+		go idle.watch(ctx, cancel, idleTimeout)
+	}
+
 	<-ctx.Done()
-	log.Println("Server shutting down")
-	return nil
+	log.Printf("Server shutting down: %v", context.Cause(ctx))
+	return session.Close()
+}
+
+// supervisionConfig reads the optional MCP_WATCH_PARENT and MCP_IDLE_TIMEOUT
+// environment variables.
+func supervisionConfig() (watchParent bool, idleTimeout time.Duration, err error) {
+	if v := os.Getenv("MCP_WATCH_PARENT"); v != "" {
+		if watchParent, err = strconv.ParseBool(v); err != nil {
+			return false, 0, fmt.Errorf("invalid MCP_WATCH_PARENT %q: %w", v, err)
+		}
+	}
+	if v := os.Getenv("MCP_IDLE_TIMEOUT"); v != "" {
+		if idleTimeout, err = time.ParseDuration(v); err != nil {
+			return false, 0, fmt.Errorf("invalid MCP_IDLE_TIMEOUT %q: %w", v, err)
+		}
+	}
+	return watchParent, idleTimeout, nil
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Reasons the stdio server shuts down, reported via context.Cause.
+var (
+	errSignal       = errors.New("received shutdown signal")
+	errDisconnected = errors.New("client disconnected")
+	errParentExited = errors.New("parent process exited")
+	errIdle         = errors.New("idle timeout reached")
+)
+
+// watchParent cancels ctx when the process that launched us goes away.
+// On Unix an orphaned process is re-parented (usually to PID 1), so a
+// change in the parent PID means the original parent has exited.
+func watchParent(ctx context.Context, cancel context.CancelCauseFunc, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	pollParent(ctx, cancel, os.Getppid, ticker.C)
+}
+
+// pollParent is watchParent with the parent PID read by getppid on every
+// tick.
+func pollParent(ctx context.Context, cancel context.CancelCauseFunc, getppid func() int, tick <-chan time.Time) {
+	ppid := getppid()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			if getppid() != ppid {
+				cancel(errParentExited)
+				return
+			}
+		}
+	}
+}
+
+// idleTracker records client activity so the server can shut itself down
+// when nobody has talked to it for a while. A request that is still being
+// handled (e.g. a long_task) keeps the session busy until it returns.
+type idleTracker struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	inFlight int
+	last     time.Time
+}
+
+// newIdleTracker returns a tracker that reads the time with now, such as
+// time.Now.
+func newIdleTracker(now func() time.Time) *idleTracker {
+	return &idleTracker{now: now, last: now()}
+}
+
+// middleware is an mcp receiving middleware that marks every inbound
+// request or notification as activity.
+func (t *idleTracker) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		t.mu.Lock()
+		t.inFlight++
+		t.last = t.now()
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			t.inFlight--
+			t.last = t.now()
+			t.mu.Unlock()
+		}()
+		return next(ctx, method, req)
+	}
+}
+
+// idleFor reports how long the session has been idle, or zero while a
+// request is in flight.
+func (t *idleTracker) idleFor(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.inFlight > 0 {
+		return 0
+	}
+	return now.Sub(t.last)
+}
+
+// watch cancels ctx once the session has been idle for longer than timeout.
+func (t *idleTracker) watch(ctx context.Context, cancel context.CancelCauseFunc, timeout time.Duration) {
+	ticker := time.NewTicker(max(timeout/4, 100*time.Millisecond))
+	defer ticker.Stop()
+	t.check(ctx, cancel, timeout, ticker.C)
+}
+
+// check is watch with the idle time checked on every tick.
+func (t *idleTracker) check(ctx context.Context, cancel context.CancelCauseFunc, timeout time.Duration, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			if t.idleFor(t.now()) >= timeout {
+				cancel(errIdle)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// afterTick sends the watcher reading tick a tick and reports whether it is
+// still running once it has handled it. Sending a second tick (or seeing the
+// watcher return) is what shows the first was handled.
+func afterTick(t *testing.T, tick chan<- time.Time, done <-chan struct{}) bool {
+	t.Helper()
+	for range 2 {
+		select {
+		case tick <- time.Time{}:
+		case <-done:
+			return false
+		case <-time.After(time.Second):
+			t.Fatal("watcher stuck")
+		}
+	}
+	return true
+}
+
+// watch runs fn in the background, returning a channel closed when it
+// returns.
+func watch(t *testing.T, fn func()) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	t.Cleanup(func() { <-done })
+	return done
+}
+
+func TestIdleTracker(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	idle := newIdleTracker(clock.Now)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	tick := make(chan time.Time)
+	done := watch(t, func() { idle.check(ctx, cancel, time.Minute, tick) })
+
+	clock.advance(30 * time.Second)
+	if !afterTick(t, tick, done) {
+		t.Fatal("stopped after 30s idle")
+	}
+
+	// A request that runs for longer than the timeout keeps the server up.
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := idle.middleware(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	finished := watch(t, func() { handler(ctx, "tools/call", nil) })
+	<-started
+	clock.advance(5 * time.Minute)
+	if !afterTick(t, tick, done) {
+		t.Fatal("stopped with a request in flight")
+	}
+
+	// The idle time counts from when the request finished.
+	close(release)
+	<-finished
+	clock.advance(59 * time.Second)
+	if !afterTick(t, tick, done) {
+		t.Fatal("stopped 59s after the last request")
+	}
+	clock.advance(time.Second)
+	if afterTick(t, tick, done) {
+		t.Fatal("still running after a minute idle")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, errIdle) {
+		t.Errorf("cause = %v, want %v", cause, errIdle)
+	}
+}
+
+func TestPollParent(t *testing.T) {
+	var ppid atomic.Int64
+	ppid.Store(42)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	tick := make(chan time.Time)
+	done := watch(t, func() { pollParent(ctx, cancel, func() int { return int(ppid.Load()) }, tick) })
+
+	if !afterTick(t, tick, done) {
+		t.Fatal("stopped while the parent is alive")
+	}
+	ppid.Store(1) // Re-parented to init
+	if afterTick(t, tick, done) {
+		t.Fatal("still running after the parent changed")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, errParentExited) {
+		t.Errorf("cause = %v, want %v", cause, errParentExited)
+	}
+}
+
+func TestPollParentStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	tick := make(chan time.Time)
+	done := watch(t, func() { pollParent(ctx, cancel, func() int { return 42 }, tick) })
+	cancel(errSignal)
+	<-done
+	if cause := context.Cause(ctx); !errors.Is(cause, errSignal) {
+		t.Errorf("cause = %v, want %v", cause, errSignal)
+	}
+}
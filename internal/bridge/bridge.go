@@ -0,0 +1,575 @@
+// Package bridge re-exposes an upstream MCP server through a local one.
+//
+// HOW IT WORKS:
+// A Proxy serves a local *mcp.Server. When a local client initializes, the
+// Proxy connects to the upstream server as an MCP *client* advertising the
+// same sampling, elicitation and roots capabilities as the local client, and
+// mirrors everything the upstream advertises — tools, resources, resource
+// templates and prompts — onto the local server. Calls on the local server
+// are forwarded upstream, and anything the upstream sends back to its client
+// (progress, logging, sampling, elicitation, roots) is forwarded to the
+// local session. Because the two sides are independent transports, this
+// lets a stdio-only client talk to a remote HTTP server and vice versa.
+//
+// A Proxy serves exactly one local session: server-initiated requests such
+// as sampling have no way to name the session they belong to, so sharing an
+// upstream between several local clients would route them ambiguously. If
+// the upstream can't be reached, the local client's initialize fails. The
+// Proxy disconnects from the upstream when the local session ends.
+//
+// NAMESPACES:
+// Attach mirrors an upstream onto an existing server alongside its own
+// features. To avoid collisions, mirrored names are prefixed: with namespace
+// "weather", tool "get_weather" becomes "weather__get_weather", prompt
+// "greet" becomes "weather__greet" and resource "about://server" becomes
+// "weather+about://server". Resource links and embedded resources in tool
+// results and prompts are renamed to match, so clients can read them here.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Proxy mirrors one upstream MCP server onto a local server.
+type Proxy struct {
+	transport mcp.Transport
+	server    *mcp.Server
+	namespace string
+
+	// standalone is set when the local server is the proxy's own (see New),
+	// so its initialize result describes the upstream.
+	standalone bool
+
+	// connMu guards connecting and closing. connected is closed once the
+	// upstream handshake has succeeded (upstream is set) or will never
+	// happen (connErr is set).
+	connMu    sync.Mutex
+	connected chan struct{}
+	upstream  atomic.Pointer[mcp.ClientSession]
+	connErr   error
+
+	// ready is set once the initial sync has run; until then upstream
+	// notifications are dropped.
+	ready atomic.Bool
+
+	// mu serializes syncs (including those triggered by list_changed
+	// notifications) and guards the names currently mirrored.
+	mu        sync.Mutex
+	tools     []string
+	prompts   []string
+	resources []string
+	templates []string
+}
+
+// New returns a Proxy whose Server mirrors the upstream server reached over
+// t. It connects to the upstream when a local client initializes, so that it
+// can advertise the sampling, elicitation and roots capabilities that client
+// has, and describes the upstream in its initialize result.
+func New(t mcp.Transport) *Proxy {
+	p := &Proxy{transport: t, standalone: true, connected: make(chan struct{})}
+	// Completions and subscriptions are only used if the upstream
+	// advertises them, since the initialize result is the upstream's.
+	p.start(mcp.NewServer(&mcp.Implementation{Name: "mcp-go-starter-bridge", Version: "1.0.0"}, &mcp.ServerOptions{
+		CompletionHandler: func(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			return p.upstream.Load().Complete(ctx, req.Params)
+		},
+		SubscribeHandler: func(ctx context.Context, req *mcp.SubscribeRequest) error {
+			params := *req.Params
+			params.URI = p.upstreamURI(params.URI)
+			return p.upstream.Load().Subscribe(ctx, &params)
+		},
+		UnsubscribeHandler: func(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+			params := *req.Params
+			params.URI = p.upstreamURI(params.URI)
+			return p.upstream.Load().Unsubscribe(ctx, &params)
+		},
+	}))
+	return p
+}
+
+// Attach returns a Proxy that mirrors the upstream server reached over t
+// onto s under the given namespace (see the package documentation). Like
+// New, it connects when a local client initializes. s should serve a single
+// session. Completions and resource subscriptions are not forwarded, since
+// they are configured when s is created.
+func Attach(s *mcp.Server, t mcp.Transport, namespace string) *Proxy {
+	p := &Proxy{transport: t, namespace: namespace, connected: make(chan struct{})}
+	p.start(s)
+	return p
+}
+
+// start makes s the local server.
+func (p *Proxy) start(s *mcp.Server) {
+	p.server = s
+	p.server.AddReceivingMiddleware(p.connectOnInitialize, p.forwardSetLevel)
+}
+
+// errClosed means the proxy was closed before it connected.
+var errClosed = errors.New("bridge: proxy closed")
+
+// connect connects to the upstream as a client with the capabilities of the
+// local client that sent params, and mirrors the upstream's features.
+func (p *Proxy) connect(ctx context.Context, params *mcp.InitializeParams) error {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+	if p.connErr != nil {
+		return p.connErr
+	}
+	if p.upstream.Load() != nil {
+		return errors.New("bridge: already connected")
+	}
+
+	upstream, err := p.client(params).Connect(ctx, p.transport, nil)
+	if err == nil {
+		p.upstream.Store(upstream)
+		if err = p.sync(ctx); err != nil {
+			_ = upstream.Close()
+		}
+	}
+	if err != nil {
+		if p.namespace != "" {
+			err = fmt.Errorf("upstream %q: %w", p.namespace, err)
+		}
+		p.connErr = err
+	}
+	close(p.connected)
+	p.ready.Store(err == nil)
+	return err
+}
+
+// client returns the client that connects to the upstream. It only
+// advertises what the local client that sent params supports, since the
+// requests it handles are forwarded there.
+func (p *Proxy) client(params *mcp.InitializeParams) *mcp.Client {
+	local := &mcp.ClientCapabilities{}
+	if params != nil && params.Capabilities != nil {
+		local = params.Capabilities
+	}
+	// Without RootsV2 the SDK would advertise roots whatever the local
+	// client supports. Root list changes aren't forwarded.
+	caps := &mcp.ClientCapabilities{}
+	if local.RootsV2 != nil {
+		caps.RootsV2 = &mcp.RootCapabilities{}
+	}
+	opts := &mcp.ClientOptions{
+		Capabilities: caps,
+		ToolListChangedHandler: func(ctx context.Context, _ *mcp.ToolListChangedRequest) {
+			if p.ready.Load() {
+				_ = p.syncTools(ctx)
+			}
+		},
+		PromptListChangedHandler: func(ctx context.Context, _ *mcp.PromptListChangedRequest) {
+			if p.ready.Load() {
+				_ = p.syncPrompts(ctx)
+			}
+		},
+		ResourceListChangedHandler: func(ctx context.Context, _ *mcp.ResourceListChangedRequest) {
+			if p.ready.Load() {
+				_ = p.syncResources(ctx)
+			}
+		},
+		ResourceUpdatedHandler: func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			if p.ready.Load() {
+				params := *req.Params
+				params.URI = p.localURI(params.URI)
+				_ = p.server.ResourceUpdated(ctx, &params)
+			}
+		},
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			if ss := p.session(); ss != nil {
+				_ = ss.Log(ctx, req.Params)
+			}
+		},
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			if ss := p.session(); ss != nil {
+				_ = ss.NotifyProgress(ctx, req.Params)
+			}
+		},
+	}
+	// A handler makes the SDK advertise its capability, so only set those
+	// the local client has, and copy its sub-capabilities (such as form or
+	// URL elicitation) exactly.
+	if local.Sampling != nil {
+		sampling := *local.Sampling
+		caps.Sampling = &sampling
+		if sampling.Tools != nil {
+			opts.CreateMessageWithToolsHandler = p.createMessageWithTools
+		} else {
+			opts.CreateMessageHandler = p.createMessage
+		}
+	}
+	if local.Elicitation != nil {
+		elicitation := *local.Elicitation
+		caps.Elicitation = &elicitation
+		opts.ElicitationHandler = p.elicit
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp-go-starter-bridge", Version: "1.0.0"}, opts)
+	if caps.RootsV2 != nil {
+		client.AddReceivingMiddleware(p.forwardRoots)
+	}
+	return client
+}
+
+// Server returns the local server mirroring the upstream.
+func (p *Proxy) Server() *mcp.Server { return p.server }
+
+// Wait blocks until the upstream connection closes, or returns at once if
+// connecting failed.
+func (p *Proxy) Wait() error {
+	<-p.connected
+	if upstream := p.upstream.Load(); upstream != nil {
+		return upstream.Wait()
+	}
+	return p.connErr
+}
+
+// Close disconnects from the upstream server, or stops the proxy from
+// connecting if it hasn't yet.
+func (p *Proxy) Close() error {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+	if upstream := p.upstream.Load(); upstream != nil {
+		return upstream.Close()
+	}
+	if p.connErr == nil {
+		p.connErr = errClosed
+		close(p.connected)
+	}
+	return nil
+}
+
+// session returns the local session, or nil if no client is connected yet.
+func (p *Proxy) session() *mcp.ServerSession {
+	if !p.ready.Load() {
+		return nil
+	}
+	for ss := range p.server.Sessions() {
+		return ss
+	}
+	return nil
+}
+
+var errNoSession = errors.New("bridge: no local client connected")
+
+// =============================================================================
+// Upstream → local: requests the upstream server sends to its client
+// =============================================================================
+
+func (p *Proxy) createMessage(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	ss := p.session()
+	if ss == nil {
+		return nil, errNoSession
+	}
+	return ss.CreateMessage(ctx, req.Params)
+}
+
+func (p *Proxy) createMessageWithTools(ctx context.Context, req *mcp.CreateMessageWithToolsRequest) (*mcp.CreateMessageWithToolsResult, error) {
+	ss := p.session()
+	if ss == nil {
+		return nil, errNoSession
+	}
+	return ss.CreateMessageWithTools(ctx, req.Params)
+}
+
+func (p *Proxy) elicit(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+	ss := p.session()
+	if ss == nil {
+		return nil, errNoSession
+	}
+	return ss.Elicit(ctx, req.Params)
+}
+
+// forwardRoots answers the upstream's roots/list with the local client's roots.
+func (p *Proxy) forwardRoots(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "roots/list" {
+			return next(ctx, method, req)
+		}
+		ss := p.session()
+		if ss == nil {
+			return next(ctx, method, req)
+		}
+		params, _ := req.GetParams().(*mcp.ListRootsParams)
+		return ss.ListRoots(ctx, params)
+	}
+}
+
+// connectOnInitialize connects to the upstream when the local client
+// initializes, and disconnects once that client's session ends.
+func (p *Proxy) connectOnInitialize(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "initialize" {
+			return next(ctx, method, req)
+		}
+		if ss, ok := req.GetSession().(*mcp.ServerSession); ok {
+			go func() {
+				_ = ss.Wait()
+				_ = p.Close()
+			}()
+		}
+		params, _ := req.GetParams().(*mcp.InitializeParams)
+		if err := p.connect(ctx, params); err != nil {
+			return nil, err
+		}
+		res, err := next(ctx, method, req)
+		if init, ok := res.(*mcp.InitializeResult); ok && p.standalone {
+			upstream := p.upstream.Load().InitializeResult()
+			init.ServerInfo = upstream.ServerInfo
+			init.Instructions = upstream.Instructions
+			init.Capabilities = upstream.Capabilities
+		}
+		return res, err
+	}
+}
+
+// forwardSetLevel passes the local client's logging level upstream, so the
+// upstream only sends the log messages the client asked for.
+func (p *Proxy) forwardSetLevel(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if upstream := p.upstream.Load(); method == "logging/setLevel" && upstream != nil {
+			if params, ok := req.GetParams().(*mcp.SetLoggingLevelParams); ok {
+				if err := upstream.SetLoggingLevel(ctx, params); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// =============================================================================
+// Mirroring: keep the local server's features in step with the upstream
+// =============================================================================
+
+func (p *Proxy) sync(ctx context.Context) error {
+	caps := p.upstream.Load().InitializeResult().Capabilities
+	if caps == nil {
+		return nil
+	}
+	if caps.Tools != nil {
+		if err := p.syncTools(ctx); err != nil {
+			return err
+		}
+	}
+	if caps.Prompts != nil {
+		if err := p.syncPrompts(ctx); err != nil {
+			return err
+		}
+	}
+	if caps.Resources != nil {
+		if err := p.syncResources(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Proxy) syncTools(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var tools []*mcp.Tool
+	for t, err := range p.upstream.Load().Tools(ctx, nil) {
+		if err != nil {
+			return err
+		}
+		tools = append(tools, t)
+	}
+
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		local := *t
+		local.Name = p.localName(t.Name)
+		p.server.AddTool(&local, p.callTool(t.Name))
+		names = append(names, local.Name)
+	}
+	p.server.RemoveTools(missing(p.tools, names)...)
+	p.tools = names
+	return nil
+}
+
+func (p *Proxy) syncPrompts(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var prompts []*mcp.Prompt
+	for pr, err := range p.upstream.Load().Prompts(ctx, nil) {
+		if err != nil {
+			return err
+		}
+		prompts = append(prompts, pr)
+	}
+
+	names := make([]string, 0, len(prompts))
+	for _, pr := range prompts {
+		local := *pr
+		local.Name = p.localName(pr.Name)
+		p.server.AddPrompt(&local, p.getPrompt(pr.Name))
+		names = append(names, local.Name)
+	}
+	p.server.RemovePrompts(missing(p.prompts, names)...)
+	p.prompts = names
+	return nil
+}
+
+func (p *Proxy) syncResources(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var resources []*mcp.Resource
+	for r, err := range p.upstream.Load().Resources(ctx, nil) {
+		if err != nil {
+			return err
+		}
+		resources = append(resources, r)
+	}
+	var templates []*mcp.ResourceTemplate
+	for rt, err := range p.upstream.Load().ResourceTemplates(ctx, nil) {
+		if err != nil {
+			return err
+		}
+		templates = append(templates, rt)
+	}
+
+	uris := make([]string, 0, len(resources))
+	for _, r := range resources {
+		local := *r
+		local.Name = p.localName(r.Name)
+		local.URI = p.localURI(r.URI)
+		p.server.AddResource(&local, p.readResource)
+		uris = append(uris, local.URI)
+	}
+	p.server.RemoveResources(missing(p.resources, uris)...)
+	p.resources = uris
+
+	uriTemplates := make([]string, 0, len(templates))
+	for _, rt := range templates {
+		local := *rt
+		local.Name = p.localName(rt.Name)
+		local.URITemplate = p.localURI(rt.URITemplate)
+		p.server.AddResourceTemplate(&local, p.readResource)
+		uriTemplates = append(uriTemplates, local.URITemplate)
+	}
+	p.server.RemoveResourceTemplates(missing(p.templates, uriTemplates)...)
+	p.templates = uriTemplates
+	return nil
+}
+
+// missing returns the elements of old that are not in current.
+func missing(old, current []string) []string {
+	keep := make(map[string]bool, len(current))
+	for _, s := range current {
+		keep[s] = true
+	}
+	var gone []string
+	for _, s := range old {
+		if !keep[s] {
+			gone = append(gone, s)
+		}
+	}
+	return gone
+}
+
+// localName maps an upstream tool or prompt name into the namespace.
+func (p *Proxy) localName(name string) string {
+	if p.namespace == "" {
+		return name
+	}
+	return p.namespace + "__" + name
+}
+
+// localURI maps an upstream resource URI (or URI template) into the namespace.
+func (p *Proxy) localURI(uri string) string {
+	if p.namespace == "" {
+		return uri
+	}
+	return p.namespace + "+" + uri
+}
+
+// upstreamURI reverses localURI.
+func (p *Proxy) upstreamURI(uri string) string {
+	if p.namespace == "" {
+		return uri
+	}
+	return strings.TrimPrefix(uri, p.namespace+"+")
+}
+
+// =============================================================================
+// Local → upstream: forwarded handlers
+//
+// Request _meta is passed through unchanged, so a progress token supplied by
+// the local client reaches the upstream and its progress notifications can be
+// relayed back. Cancelling the local request cancels the upstream call.
+// =============================================================================
+
+func (p *Proxy) callTool(name string) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		params := &mcp.CallToolParams{Meta: req.Params.Meta, Name: name}
+		if len(req.Params.Arguments) > 0 {
+			params.Arguments = req.Params.Arguments
+		}
+		res, err := p.upstream.Load().CallTool(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range res.Content {
+			p.localizeContent(c)
+		}
+		return res, nil
+	}
+}
+
+func (p *Proxy) getPrompt(name string) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		params := *req.Params
+		params.Name = name
+		res, err := p.upstream.Load().GetPrompt(ctx, &params)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range res.Messages {
+			p.localizeContent(m.Content)
+		}
+		return res, nil
+	}
+}
+
+// localizeContent maps the URIs of resource links and embedded resources in
+// upstream content into the namespace. Web links are left alone, since
+// clients open those themselves rather than reading them from the server.
+func (p *Proxy) localizeContent(c mcp.Content) {
+	switch c := c.(type) {
+	case *mcp.ResourceLink:
+		if !isWebURI(c.URI) {
+			c.URI = p.localURI(c.URI)
+		}
+	case *mcp.EmbeddedResource:
+		if c.Resource != nil && !isWebURI(c.Resource.URI) {
+			c.Resource.URI = p.localURI(c.Resource.URI)
+		}
+	}
+}
+
+func isWebURI(uri string) bool {
+	return strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+}
+
+func (p *Proxy) readResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	params := *req.Params
+	params.URI = p.upstreamURI(params.URI)
+	res, err := p.upstream.Load().ReadResource(ctx, &params)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range res.Contents {
+		c.URI = p.localURI(c.URI)
+	}
+	return res, nil
+}
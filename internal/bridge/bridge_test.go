@@ -0,0 +1,259 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// upstreamServer returns a server whose tools make requests of their client:
+//   - sample: sampling/createMessage, returning the reply's text
+//   - ask: elicitation/create, returning the answer's "name"
+//   - roots: roots/list, returning the first root's URI
+//   - count: three progress notifications
+func upstreamServer() *mcp.Server {
+	s := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "2.0.0"}, &mcp.ServerOptions{Instructions: "Upstream instructions"})
+	text := func(s string) *mcp.CallToolResult {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: s}}}
+	}
+	mcp.AddTool(s, &mcp.Tool{Name: "sample"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		res, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+			Messages:  []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: "hi"}}},
+			MaxTokens: 10,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return text(res.Content.(*mcp.TextContent).Text), nil, nil
+	})
+	mcp.AddTool(s, &mcp.Tool{Name: "ask"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		res, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message: "Name?",
+			RequestedSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"name": map[string]any{"type": "string"}},
+			},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return text(fmt.Sprintf("%s %v", res.Action, res.Content["name"])), nil, nil
+	})
+	mcp.AddTool(s, &mcp.Tool{Name: "roots"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		res, err := req.Session.ListRoots(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(res.Roots) == 0 {
+			return text("no roots"), nil, nil
+		}
+		return text(res.Roots[0].URI), nil, nil
+	})
+	mcp.AddTool(s, &mcp.Tool{Name: "count"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		for i := range 3 {
+			err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: req.Params.GetProgressToken(),
+				Progress:      float64(i + 1),
+				Total:         3,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return text("counted"), nil, nil
+	})
+	return s
+}
+
+// connectProxy connects client to a Proxy for upstreamServer, returning the
+// client's session and the upstream's session with the proxy.
+func connectProxy(t *testing.T, client *mcp.Client) (*mcp.ClientSession, *mcp.ServerSession) {
+	t.Helper()
+	ctx := context.Background()
+	upstreamClient, upstreamServerTransport := mcp.NewInMemoryTransports()
+	upstream, err := upstreamServer().Connect(ctx, upstreamServerTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { upstream.Close() })
+
+	p := New(upstreamClient)
+	t.Cleanup(func() { p.Close() })
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := p.Server().Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs, upstream
+}
+
+// callText calls a tool and returns its text.
+func callText(t *testing.T, cs *mcp.ClientSession, params *mcp.CallToolParams) string {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if res.IsError {
+		t.Fatalf("%s failed: %s", params.Name, text)
+	}
+	return text
+}
+
+func newClient(opts *mcp.ClientOptions) *mcp.Client {
+	return mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, opts)
+}
+
+func TestProxyDescribesUpstream(t *testing.T) {
+	cs, _ := connectProxy(t, newClient(nil))
+	init := cs.InitializeResult()
+	if init.ServerInfo.Name != "upstream" || init.Instructions != "Upstream instructions" {
+		t.Errorf("initialize result = %+v %q, want the upstream's", init.ServerInfo, init.Instructions)
+	}
+	if init.Capabilities.Completions != nil || init.Capabilities.Tools == nil {
+		t.Errorf("capabilities = %+v, want the upstream's", init.Capabilities)
+	}
+}
+
+func TestProxyMirrorsClientCapabilities(t *testing.T) {
+	elicit := func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) { return nil, errors.New("unused") }
+	sample := func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return nil, errors.New("unused")
+	}
+	tests := []struct {
+		name                      string
+		opts                      *mcp.ClientOptions
+		sampling, elicitURL, root bool
+		elicitation               bool
+	}{
+		{
+			name: "none",
+			opts: &mcp.ClientOptions{Capabilities: &mcp.ClientCapabilities{}},
+		},
+		{
+			name:        "form elicitation only",
+			opts:        &mcp.ClientOptions{Capabilities: &mcp.ClientCapabilities{}, ElicitationHandler: elicit},
+			elicitation: true,
+		},
+		{
+			name: "everything",
+			opts: &mcp.ClientOptions{
+				Capabilities: &mcp.ClientCapabilities{
+					RootsV2: &mcp.RootCapabilities{},
+					Elicitation: &mcp.ElicitationCapabilities{
+						Form: &mcp.FormElicitationCapabilities{},
+						URL:  &mcp.URLElicitationCapabilities{},
+					},
+				},
+				ElicitationHandler:   elicit,
+				CreateMessageHandler: sample,
+			},
+			sampling: true, elicitation: true, elicitURL: true, root: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, upstream := connectProxy(t, newClient(tt.opts))
+			caps := upstream.InitializeParams().Capabilities
+			if got := caps.Sampling != nil; got != tt.sampling {
+				t.Errorf("sampling advertised: %v, want %v", got, tt.sampling)
+			}
+			if got := caps.Elicitation != nil; got != tt.elicitation {
+				t.Errorf("elicitation advertised: %v, want %v", got, tt.elicitation)
+			}
+			if got := caps.Elicitation != nil && caps.Elicitation.URL != nil; got != tt.elicitURL {
+				t.Errorf("URL elicitation advertised: %v, want %v", got, tt.elicitURL)
+			}
+			if got := caps.RootsV2 != nil; got != tt.root {
+				t.Errorf("roots advertised: %v, want %v", got, tt.root)
+			}
+		})
+	}
+}
+
+func TestProxyForwardsSampling(t *testing.T) {
+	cs, _ := connectProxy(t, newClient(&mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			prompt := req.Params.Messages[0].Content.(*mcp.TextContent).Text
+			return &mcp.CreateMessageResult{Role: "assistant", Model: "test", Content: &mcp.TextContent{Text: "reply to " + prompt}}, nil
+		},
+	}))
+	if got := callText(t, cs, &mcp.CallToolParams{Name: "sample"}); got != "reply to hi" {
+		t.Errorf("sample = %q", got)
+	}
+}
+
+func TestProxyForwardsElicitation(t *testing.T) {
+	cs, _ := connectProxy(t, newClient(&mcp.ClientOptions{
+		ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			if req.Params.Message != "Name?" {
+				return nil, fmt.Errorf("message = %q", req.Params.Message)
+			}
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"name": "Ada"}}, nil
+		},
+	}))
+	if got := callText(t, cs, &mcp.CallToolParams{Name: "ask"}); got != "accept Ada" {
+		t.Errorf("ask = %q", got)
+	}
+}
+
+func TestProxyForwardsRoots(t *testing.T) {
+	client := newClient(nil)
+	client.AddRoots(&mcp.Root{URI: "file:///work"})
+	cs, _ := connectProxy(t, client)
+	if got := callText(t, cs, &mcp.CallToolParams{Name: "roots"}); got != "file:///work" {
+		t.Errorf("roots = %q", got)
+	}
+}
+
+func TestProxyForwardsProgress(t *testing.T) {
+	progress := make(chan float64, 3)
+	cs, _ := connectProxy(t, newClient(&mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			if req.Params.ProgressToken == "tok" {
+				progress <- req.Params.Progress
+			}
+		},
+	}))
+	// Not SetProgressToken, which loses the token when Meta is nil.
+	params := &mcp.CallToolParams{Name: "count", Meta: mcp.Meta{"progressToken": "tok"}}
+	if got := callText(t, cs, params); got != "counted" {
+		t.Errorf("count = %q", got)
+	}
+	for want := 1.0; want <= 3; want++ {
+		if got := <-progress; got != want {
+			t.Errorf("progress = %v, want %v", got, want)
+		}
+	}
+}
+
+// unreachable is a transport that can't connect.
+type unreachable struct{}
+
+func (unreachable) Connect(context.Context) (mcp.Connection, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestProxyUnreachableUpstream(t *testing.T) {
+	ctx := context.Background()
+	p := New(unreachable{})
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := p.Server().Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cs, err := newClient(nil).Connect(ctx, clientTransport, nil); err == nil {
+		cs.Close()
+		t.Fatal("initialize succeeded without an upstream")
+	}
+	if err := p.Wait(); err == nil {
+		t.Error("Wait returned nil after connecting failed")
+	}
+}
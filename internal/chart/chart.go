@@ -0,0 +1,385 @@
+// Package chart renders simple line and bar charts to PNG.
+//
+// It backs the render_chart and chart_forecast tools, and uses only the
+// standard library: shapes are drawn pixel by pixel and text comes from a
+// small built-in bitmap font (see font.go), which has upper-case letters,
+// digits and a little punctuation. Exact values belong in text next to the
+// image; the chart is for the shape of the data.
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+// Kind is the type of chart.
+type Kind string
+
+const (
+	Line Kind = "line"
+	Bar  Kind = "bar"
+)
+
+// Size limits, in pixels.
+const (
+	DefaultWidth  = 640
+	DefaultHeight = 360
+	MinWidth      = 240
+	MinHeight     = 160
+	MaxWidth      = 1600
+	MaxHeight     = 1200
+)
+
+// Data limits. Larger values would need axis labels wider than the chart.
+const (
+	MaxSeries = 8
+	MaxPoints = 200
+	MaxValue  = 1e9 // Largest magnitude of a value
+)
+
+// Series is one named sequence of values.
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// Chart describes a chart to render. Every series must have the same number
+// of values; Labels, if set, names each position along the x axis.
+type Chart struct {
+	Kind   Kind
+	Title  string
+	Labels []string
+	Series []Series
+	Width  int // DefaultWidth if 0
+	Height int // DefaultHeight if 0
+}
+
+// palette colours the series in order.
+var palette = []color.RGBA{
+	{31, 119, 180, 255},
+	{255, 127, 14, 255},
+	{44, 160, 44, 255},
+	{214, 39, 40, 255},
+	{148, 103, 189, 255},
+	{140, 86, 75, 255},
+	{227, 119, 194, 255},
+	{127, 127, 127, 255},
+}
+
+var (
+	background = color.RGBA{255, 255, 255, 255}
+	ink        = color.RGBA{51, 51, 51, 255}
+	grid       = color.RGBA{224, 224, 224, 255}
+)
+
+// Validate checks that c can be rendered.
+func (c Chart) Validate() error {
+	if c.Kind != Line && c.Kind != Bar {
+		return fmt.Errorf("unknown chart kind %q (want %q or %q)", c.Kind, Line, Bar)
+	}
+	if len(c.Series) == 0 {
+		return errors.New("at least one series is required")
+	}
+	if len(c.Series) > MaxSeries {
+		return fmt.Errorf("at most %d series can be drawn, got %d", MaxSeries, len(c.Series))
+	}
+	n := len(c.Series[0].Values)
+	if n == 0 {
+		return errors.New("series have no values")
+	}
+	if n > MaxPoints {
+		return fmt.Errorf("at most %d values per series can be drawn, got %d", MaxPoints, n)
+	}
+	for i, s := range c.Series {
+		if len(s.Values) != n {
+			return fmt.Errorf("series %d has %d values, series 1 has %d; all series need the same number", i+1, len(s.Values), n)
+		}
+		for j, v := range s.Values {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("series %d value %d is not a finite number", i+1, j+1)
+			}
+			if math.Abs(v) > MaxValue {
+				return fmt.Errorf("series %d value %d is outside ±%g", i+1, j+1, float64(MaxValue))
+			}
+		}
+	}
+	if len(c.Labels) > 0 && len(c.Labels) != n {
+		return fmt.Errorf("%d labels for %d values per series", len(c.Labels), n)
+	}
+	w, h := c.size()
+	if w < MinWidth || w > MaxWidth {
+		return fmt.Errorf("width must be between %d and %d", MinWidth, MaxWidth)
+	}
+	if h < MinHeight || h > MaxHeight {
+		return fmt.Errorf("height must be between %d and %d", MinHeight, MaxHeight)
+	}
+	return nil
+}
+
+func (c Chart) size() (int, int) {
+	w, h := c.Width, c.Height
+	if w == 0 {
+		w = DefaultWidth
+	}
+	if h == 0 {
+		h = DefaultHeight
+	}
+	return w, h
+}
+
+// PNG renders c and encodes it as a PNG.
+func (c Chart) PNG() ([]byte, error) {
+	img, err := c.Render()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Render draws c.
+func (c Chart) Render() (*image.RGBA, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	w, h := c.size()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	lo, hi := c.valueRange()
+	ticks := niceTicks(lo, hi, 5)
+	lo, hi = ticks[0], ticks[len(ticks)-1]
+
+	// Leave room for the title above, the y-axis labels on the left, and
+	// the x-axis labels and legend below.
+	left := 12 + textWidth(longestTick(ticks), 2)
+	top := 16
+	if c.Title != "" {
+		top += glyphHeight*3 + 12
+	}
+	bottom := h - 16 - glyphHeight*2
+	if len(c.Labels) > 0 {
+		bottom -= glyphHeight*2 + 8
+	}
+	if c.hasLegend() {
+		bottom -= glyphHeight*2 + 12
+	}
+	plot := image.Rect(left, top, w-16, bottom)
+
+	y := func(v float64) int {
+		return plot.Max.Y - int(math.Round((v-lo)/(hi-lo)*float64(plot.Dy())))
+	}
+
+	if c.Title != "" {
+		drawText(img, c.Title, (w-textWidth(c.Title, 3))/2, 12, 3, ink)
+	}
+	for _, t := range ticks {
+		ty := y(t)
+		hline(img, plot.Min.X, plot.Max.X, ty, grid)
+		label := formatTick(t, ticks)
+		drawText(img, label, plot.Min.X-6-textWidth(label, 2), ty-glyphHeight, 2, ink)
+	}
+
+	n := len(c.Series[0].Values)
+	slot := float64(plot.Dx()) / float64(n)
+	x := func(i int) int { return plot.Min.X + int(slot*(float64(i)+0.5)) }
+
+	switch c.Kind {
+	case Line:
+		for si, s := range c.Series {
+			col := palette[si%len(palette)]
+			for i, v := range s.Values {
+				if i > 0 {
+					line(img, x(i-1), y(s.Values[i-1]), x(i), y(v), col)
+				}
+				fill(img, image.Rect(x(i)-2, y(v)-2, x(i)+3, y(v)+3), col)
+			}
+		}
+	case Bar:
+		base := y(math.Max(lo, math.Min(0, hi)))
+		group := slot * 0.8
+		barWidth := group / float64(len(c.Series))
+		for si, s := range c.Series {
+			col := palette[si%len(palette)]
+			for i, v := range s.Values {
+				x0 := plot.Min.X + int(slot*float64(i)+(slot-group)/2+barWidth*float64(si))
+				x1 := plot.Min.X + int(slot*float64(i)+(slot-group)/2+barWidth*float64(si+1))
+				if x1-x0 > 2 {
+					x1-- // A gap between neighbouring bars
+				}
+				fill(img, image.Rect(x0, min(y(v), base), max(x1, x0+1), max(y(v), base)+1), col)
+			}
+		}
+	}
+
+	// Axes go on top of the data so that bars don't hide them.
+	vline(img, plot.Min.X, plot.Min.Y, plot.Max.Y, ink)
+	hline(img, plot.Min.X, plot.Max.X, plot.Max.Y, ink)
+
+	next := bottom + 8
+	if len(c.Labels) > 0 {
+		// Skip labels that would overlap their neighbours.
+		widest := 0
+		for _, l := range c.Labels {
+			widest = max(widest, textWidth(l, 2))
+		}
+		step := max(1, int(math.Ceil(float64(widest+8)/slot)))
+		for i := 0; i < n; i += step {
+			drawText(img, c.Labels[i], x(i)-textWidth(c.Labels[i], 2)/2, next, 2, ink)
+		}
+		next += glyphHeight*2 + 8
+	}
+	if c.hasLegend() {
+		lx := plot.Min.X
+		for si, s := range c.Series {
+			fill(img, image.Rect(lx, next+4, lx+glyphHeight*2, next+4+glyphHeight*2), palette[si%len(palette)])
+			lx += glyphHeight*2 + 6
+			drawText(img, s.Name, lx, next+4, 2, ink)
+			lx += textWidth(s.Name, 2) + 16
+		}
+	}
+	return img, nil
+}
+
+func (c Chart) hasLegend() bool {
+	for _, s := range c.Series {
+		if s.Name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// valueRange returns the smallest and largest values, widened to include 0
+// for bar charts, whose bars start there.
+func (c Chart) valueRange() (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if c.Kind == Bar {
+		lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	// A range that is tiny next to its values would need ticks with more
+	// decimals than fit, or too small to step through; widen it.
+	if span := 1e-6 * math.Max(1, math.Max(math.Abs(lo), math.Abs(hi))); hi-lo < span {
+		mid := lo/2 + hi/2
+		lo, hi = mid-span/2, mid+span/2
+	}
+	return lo, hi
+}
+
+// niceTicks returns about n evenly spaced round values covering lo to hi.
+func niceTicks(lo, hi float64, n int) []float64 {
+	raw := (hi - lo) / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag * 10
+	for _, m := range []float64{1, 2, 2.5, 5} {
+		if raw <= m*mag {
+			step = m * mag
+			break
+		}
+	}
+	start := math.Floor(lo/step) * step
+	// Multiply rather than add up steps, which would accumulate rounding
+	// errors.
+	var ticks []float64
+	for i := 0; len(ticks) < 2 || ticks[len(ticks)-1] < hi; i++ {
+		ticks = append(ticks, start+float64(i)*step)
+	}
+	return ticks
+}
+
+// formatTick formats t with as many decimals as the tick spacing needs.
+func formatTick(t float64, ticks []float64) string {
+	step := ticks[1] - ticks[0]
+	decimals := max(0, int(math.Ceil(-math.Log10(step)-1e-9)))
+	if scaled := step * math.Pow(10, float64(decimals)); math.Abs(scaled-math.Round(scaled)) > 1e-6 {
+		decimals++ // 2.5, 0.25, ...
+	}
+	if math.Abs(t) < step/1e6 {
+		t = 0 // Not -0
+	}
+	return strconv.FormatFloat(t, 'f', decimals, 64)
+}
+
+func longestTick(ticks []float64) string {
+	longest := ""
+	for _, t := range ticks {
+		if s := formatTick(t, ticks); len(s) > len(longest) {
+			longest = s
+		}
+	}
+	return longest
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r.Intersect(img.Bounds()), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func hline(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	for x := x0; x <= x1; x++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+func vline(img *image.RGBA, x, y0, y1 int, c color.RGBA) {
+	for y := y0; y <= y1; y++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// line draws a 2-pixel-wide line from (x0, y0) to (x1, y1) with
+// Bresenham's algorithm.
+func line(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		fill(img, image.Rect(x0, y0, x0+2, y0+2), c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestValidateRejects(t *testing.T) {
+	one := []Series{{Values: []float64{1, 2, 3}}}
+	tests := []struct {
+		name  string
+		chart Chart
+		want  string // In the error
+	}{
+		{"unknown kind", Chart{Kind: "pie", Series: one}, "unknown chart kind"},
+		{"no series", Chart{Kind: Line}, "at least one series"},
+		{"empty series", Chart{Kind: Line, Series: []Series{{}}}, "no values"},
+		{"too many series", Chart{Kind: Line, Series: make([]Series, MaxSeries+1)}, "at most"},
+		{"too many values", Chart{Kind: Line, Series: []Series{{Values: make([]float64, MaxPoints+1)}}}, "at most"},
+		{"series lengths differ", Chart{Kind: Bar, Series: []Series{{Values: []float64{1, 2}}, {Values: []float64{1}}}}, "same number"},
+		{"NaN", Chart{Kind: Line, Series: []Series{{Values: []float64{1, math.NaN()}}}}, "not a finite number"},
+		{"+Inf", Chart{Kind: Line, Series: []Series{{Values: []float64{math.Inf(1)}}}}, "not a finite number"},
+		{"-Inf", Chart{Kind: Bar, Series: []Series{{Values: []float64{1, math.Inf(-1)}}}}, "not a finite number"},
+		{"too large", Chart{Kind: Line, Series: []Series{{Values: []float64{1, 1e300}}}}, "outside"},
+		{"too few labels", Chart{Kind: Line, Labels: []string{"a", "b"}, Series: one}, "2 labels for 3 values"},
+		{"too many labels", Chart{Kind: Bar, Labels: []string{"a", "b", "c", "d"}, Series: one}, "4 labels for 3 values"},
+		{"too narrow", Chart{Kind: Line, Series: one, Width: MinWidth - 1}, "width"},
+		{"too tall", Chart{Kind: Line, Series: one, Height: MaxHeight + 1}, "height"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.chart.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+			if _, err := tt.chart.PNG(); err == nil {
+				t.Error("PNG() succeeded")
+			}
+		})
+	}
+}
+
+func TestPNG(t *testing.T) {
+	tests := []struct {
+		name          string
+		chart         Chart
+		width, height int
+	}{
+		{"default size", Chart{Kind: Line, Series: []Series{{Values: []float64{1, 3, 2}}}}, DefaultWidth, DefaultHeight},
+		{"single point line", Chart{Kind: Line, Series: []Series{{Values: []float64{7}}}, Width: MinWidth, Height: MinHeight}, MinWidth, MinHeight},
+		{"single point bar", Chart{Kind: Bar, Labels: []string{"only"}, Series: []Series{{Name: "a", Values: []float64{-7}}}}, DefaultWidth, DefaultHeight},
+		{"constant", Chart{Kind: Line, Series: []Series{{Values: []float64{5, 5, 5}}}}, DefaultWidth, DefaultHeight},
+		{"zeros", Chart{Kind: Bar, Series: []Series{{Values: []float64{0, 0}}}}, DefaultWidth, DefaultHeight},
+		{"tiny range", Chart{Kind: Line, Series: []Series{{Values: []float64{1, 1 + 1e-12, 1 + 2e-12}}}}, DefaultWidth, DefaultHeight},
+		{"denormal range", Chart{Kind: Line, Series: []Series{{Values: []float64{0, math.SmallestNonzeroFloat64}}}}, DefaultWidth, DefaultHeight},
+		{"largest values", Chart{Kind: Bar, Series: []Series{{Values: []float64{-MaxValue, MaxValue}}}, Width: MinWidth, Height: MinHeight}, MinWidth, MinHeight},
+		{"everything", Chart{
+			Kind:   Bar,
+			Title:  "Everything",
+			Labels: []string{"mon", "tue", "wed"},
+			Series: []Series{{Name: "high", Values: []float64{3.5, 4, -1}}, {Name: "low", Values: []float64{1, 2, -3}}},
+			Width:  MaxWidth,
+			Height: MaxHeight,
+		}, MaxWidth, MaxHeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.chart.PNG()
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Errorf("image is %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+			// The first series' colour shows somewhere: the data was drawn.
+			want := palette[0]
+			found := false
+			for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y && !found; y++ {
+				for x := img.Bounds().Min.X; x < img.Bounds().Max.X && !found; x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					found = uint8(r>>8) == want.R && uint8(g>>8) == want.G && uint8(b>>8) == want.B
+				}
+			}
+			if !found {
+				t.Error("no data drawn")
+			}
+		})
+	}
+}
+
+func TestNiceTicks(t *testing.T) {
+	for _, r := range [][2]float64{{0, 1}, {-3, 17}, {0.001, 0.0042}, {1e8, 1e9}, {-MaxValue, MaxValue}} {
+		ticks := niceTicks(r[0], r[1], 5)
+		if len(ticks) < 2 || len(ticks) > 12 || ticks[0] > r[0] || ticks[len(ticks)-1] < r[1] {
+			t.Errorf("niceTicks(%v, %v) = %v, want a few ticks covering the range", r[0], r[1], ticks)
+		}
+	}
+}
+
+func TestValueRangeWidensTinyRanges(t *testing.T) {
+	c := Chart{Kind: Line, Series: []Series{{Values: []float64{1e6, 1e6 + 1e-9}}}}
+	lo, hi := c.valueRange()
+	if hi-lo < 1 || lo > 1e6 || hi < 1e6+1e-9 {
+		t.Errorf("valueRange() = %v, %v, want at least 1 wide around 1e6", lo, hi)
+	}
+	if ticks := niceTicks(lo, hi, 5); len(ticks) > 12 {
+		t.Errorf("%d ticks", len(ticks))
+	}
+}
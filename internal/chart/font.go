@@ -0,0 +1,97 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"unicode"
+)
+
+// A 3x5 pixel font, drawn at a whole-number scale. Each glyph is five rows
+// of three pixels, "#" for ink. Lower-case letters are drawn as upper-case,
+// and characters the font lacks as "?".
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+)
+
+var glyphs = map[rune]string{
+	'0': "###" + "#.#" + "#.#" + "#.#" + "###",
+	'1': ".#." + "##." + ".#." + ".#." + "###",
+	'2': "###" + "..#" + "###" + "#.." + "###",
+	'3': "###" + "..#" + "###" + "..#" + "###",
+	'4': "#.#" + "#.#" + "###" + "..#" + "..#",
+	'5': "###" + "#.." + "###" + "..#" + "###",
+	'6': "###" + "#.." + "###" + "#.#" + "###",
+	'7': "###" + "..#" + "..#" + "..#" + "..#",
+	'8': "###" + "#.#" + "###" + "#.#" + "###",
+	'9': "###" + "#.#" + "###" + "..#" + "###",
+	'A': ".#." + "#.#" + "###" + "#.#" + "#.#",
+	'B': "##." + "#.#" + "##." + "#.#" + "##.",
+	'C': ".##" + "#.." + "#.." + "#.." + ".##",
+	'D': "##." + "#.#" + "#.#" + "#.#" + "##.",
+	'E': "###" + "#.." + "##." + "#.." + "###",
+	'F': "###" + "#.." + "##." + "#.." + "#..",
+	'G': ".##" + "#.." + "#.#" + "#.#" + ".##",
+	'H': "#.#" + "#.#" + "###" + "#.#" + "#.#",
+	'I': "###" + ".#." + ".#." + ".#." + "###",
+	'J': "..#" + "..#" + "..#" + "#.#" + ".#.",
+	'K': "#.#" + "#.#" + "##." + "#.#" + "#.#",
+	'L': "#.." + "#.." + "#.." + "#.." + "###",
+	'M': "#.#" + "###" + "###" + "#.#" + "#.#",
+	'N': "##." + "#.#" + "#.#" + "#.#" + "#.#",
+	'O': ".#." + "#.#" + "#.#" + "#.#" + ".#.",
+	'P': "##." + "#.#" + "##." + "#.." + "#..",
+	'Q': ".#." + "#.#" + "#.#" + "##." + ".##",
+	'R': "##." + "#.#" + "##." + "#.#" + "#.#",
+	'S': ".##" + "#.." + ".#." + "..#" + "##.",
+	'T': "###" + ".#." + ".#." + ".#." + ".#.",
+	'U': "#.#" + "#.#" + "#.#" + "#.#" + "###",
+	'V': "#.#" + "#.#" + "#.#" + "#.#" + ".#.",
+	'W': "#.#" + "#.#" + "###" + "###" + "#.#",
+	'X': "#.#" + "#.#" + ".#." + "#.#" + "#.#",
+	'Y': "#.#" + "#.#" + ".#." + ".#." + ".#.",
+	'Z': "###" + "..#" + ".#." + "#.." + "###",
+	' ': "..." + "..." + "..." + "..." + "...",
+	'-': "..." + "..." + "###" + "..." + "...",
+	'+': "..." + ".#." + "###" + ".#." + "...",
+	'.': "..." + "..." + "..." + "..." + ".#.",
+	',': "..." + "..." + "..." + ".#." + "#..",
+	':': "..." + ".#." + "..." + ".#." + "...",
+	'/': "..#" + "..#" + ".#." + "#.." + "#..",
+	'%': "#.." + "..#" + ".#." + "#.." + "..#",
+	'(': ".#." + "#.." + "#.." + "#.." + ".#.",
+	')': ".#." + "..#" + "..#" + "..#" + ".#.",
+	'°': "##." + "##." + "..." + "..." + "...",
+	'?': "###" + "..#" + ".#." + "..." + ".#.",
+}
+
+func glyph(r rune) string {
+	if g, ok := glyphs[unicode.ToUpper(r)]; ok {
+		return g
+	}
+	return glyphs['?']
+}
+
+// textWidth is the width in pixels of s drawn at scale.
+func textWidth(s string, scale int) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+1) - 1) * scale
+}
+
+// drawText draws s with its top-left corner at (x, y).
+func drawText(img *image.RGBA, s string, x, y, scale int, c color.RGBA) {
+	for _, r := range s {
+		g := glyph(r)
+		for i := 0; i < glyphWidth*glyphHeight; i++ {
+			if g[i] != '#' {
+				continue
+			}
+			px, py := x+i%glyphWidth*scale, y+i/glyphWidth*scale
+			fill(img, image.Rect(px, py, px+scale, py+scale), c)
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
@@ -0,0 +1,466 @@
+package elicit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ValidationError lists what is wrong with accepted content, one problem per
+// field.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid response: " + strings.Join(e.Problems, "; ")
+}
+
+// SchemaFor derives a flat elicitation schema from struct type T.
+func SchemaFor[T any]() (map[string]any, error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("elicit: %v is not a struct", t)
+	}
+
+	props := make(map[string]any)
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, omitempty := jsonName(f)
+		if name == "-" {
+			continue
+		}
+		prop, err := property(f)
+		if err != nil {
+			return nil, fmt.Errorf("elicit: %v.%s: %w", t, f.Name, err)
+		}
+		props[name] = prop
+		if !omitempty && f.Type.Kind() != reflect.Pointer && !optional(f) {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// MustSchemaFor is like SchemaFor but panics on error. Use it for forms
+// defined in code, where an error is a programming mistake.
+func MustSchemaFor[T any]() map[string]any {
+	schema, err := SchemaFor[T]()
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Decode validates accepted content against T's schema and decodes it into a
+// T. Invalid content gives a *ValidationError.
+func Decode[T any](content map[string]any) (T, error) {
+	var v T
+	schema, err := SchemaFor[T]()
+	if err != nil {
+		return v, err
+	}
+	if problems := Validate(schema, content); len(problems) > 0 {
+		return v, &ValidationError{Problems: problems}
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return v, err
+	}
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// Ask shows a single form derived from T and decodes the answer. It returns
+// ErrDeclined or ErrCancelled if the user doesn't accept, and a
+// *ValidationError if the answer is invalid. Use a Wizard to re-prompt.
+func Ask[T any](ctx context.Context, s Session, message string) (T, error) {
+	var v T
+	schema, err := SchemaFor[T]()
+	if err != nil {
+		return v, err
+	}
+	result, err := s.Elicit(ctx, &mcp.ElicitParams{
+		Message:         message,
+		RequestedSchema: schema,
+	})
+	if err != nil {
+		return v, err
+	}
+	switch result.Action {
+	case "accept":
+		return Decode[T](result.Content)
+	case "decline":
+		return v, ErrDeclined
+	case "cancel":
+		return v, ErrCancelled
+	default:
+		return v, fmt.Errorf("unexpected elicitation action %q", result.Action)
+	}
+}
+
+func jsonName(f reflect.StructField) (name string, omitempty bool) {
+	tag := f.Tag.Get("json")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, slices.Contains(strings.Split(opts, ","), "omitempty")
+}
+
+// property builds the schema of one field from its type and elicit tag.
+func property(f reflect.StructField) (map[string]any, error) {
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	prop := make(map[string]any)
+	switch t.Kind() {
+	case reflect.String:
+		prop["type"] = "string"
+	case reflect.Bool:
+		prop["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		prop["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		prop["type"] = "number"
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported type %v", f.Type)
+		}
+		prop["type"] = "array"
+	default:
+		return nil, fmt.Errorf("unsupported type %v", f.Type)
+	}
+
+	tag, ok := f.Tag.Lookup("elicit")
+	if !ok {
+		if prop["type"] == "array" {
+			return nil, fmt.Errorf("[]string fields need an enum")
+		}
+		return prop, nil
+	}
+	for _, opt := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		if err := setOption(prop, t, key, value); err != nil {
+			return nil, err
+		}
+	}
+	if prop["type"] == "array" && prop["items"] == nil {
+		return nil, fmt.Errorf("[]string fields need an enum")
+	}
+	return prop, nil
+}
+
+// optional reports whether f's elicit tag has the bare "optional" option.
+func optional(f reflect.StructField) bool {
+	for _, opt := range strings.Split(f.Tag.Get("elicit"), ";") {
+		if strings.TrimSpace(opt) == "optional" {
+			return true
+		}
+	}
+	return false
+}
+
+// setOption applies one elicit tag option to prop; t is the field's type.
+func setOption(prop map[string]any, t reflect.Type, key, value string) error {
+	typ := prop["type"]
+	switch key {
+	case "", "optional":
+	case "title", "description":
+		prop[key] = value
+	case "format":
+		if typ != "string" {
+			return fmt.Errorf("format is only for strings")
+		}
+		if !slices.Contains([]string{"email", "uri", "date", "date-time"}, value) {
+			return fmt.Errorf("unsupported format %q (want email, uri, date or date-time)", value)
+		}
+		prop["format"] = value
+	case "minLength", "maxLength":
+		if typ != "string" {
+			return fmt.Errorf("%s is only for strings", key)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s=%q is not a non-negative integer", key, value)
+		}
+		prop[key] = n
+	case "min", "max":
+		if typ != "integer" && typ != "number" {
+			return fmt.Errorf("%s is only for numbers", key)
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s=%q is not a number", key, value)
+		}
+		prop[map[string]string{"min": "minimum", "max": "maximum"}[key]] = n
+	case "default":
+		v, err := parseDefault(t, value)
+		if err != nil {
+			return err
+		}
+		prop["default"] = v
+	case "enum":
+		if typ != "string" && typ != "array" {
+			return fmt.Errorf("enum is only for strings and []string")
+		}
+		var values []string
+		var options []map[string]any
+		for _, option := range strings.Split(value, "|") {
+			v, title, titled := strings.Cut(option, ":")
+			values = append(values, v)
+			if titled {
+				options = append(options, map[string]any{"const": v, "title": title})
+			}
+		}
+		if options != nil && len(options) != len(values) {
+			return fmt.Errorf("enum: give a title for every value or none")
+		}
+		// Titled enums use oneOf (single) or anyOf (multi-select) of consts.
+		switch {
+		case typ == "string" && options == nil:
+			prop["enum"] = values
+		case typ == "string":
+			prop["oneOf"] = options
+		case options == nil:
+			prop["items"] = map[string]any{"type": "string", "enum": values}
+		default:
+			prop["items"] = map[string]any{"anyOf": options}
+		}
+	default:
+		return fmt.Errorf("unknown elicit option %q", key)
+	}
+	return nil
+}
+
+// parseDefault parses a default value as t's kind.
+func parseDefault(t reflect.Type, value string) (any, error) {
+	var (
+		v   any
+		err error
+	)
+	switch t.Kind() {
+	case reflect.String:
+		v = value
+	case reflect.Bool:
+		v, err = strconv.ParseBool(value)
+	case reflect.Slice:
+		v = strings.Split(value, "|")
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(value, 64)
+	default:
+		v, err = strconv.ParseInt(value, 10, 64)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("default=%q is not a valid %v", value, t)
+	}
+	return v, nil
+}
+
+// Validate checks values against a flat elicitation schema and returns one
+// message per problem, naming the field. It checks required fields, types,
+// enums, string lengths and formats (email, uri, date, date-time) and number
+// ranges.
+func Validate(schema map[string]any, values map[string]any) []string {
+	var problems []string
+	props, _ := schema["properties"].(map[string]any)
+
+	var required []string
+	switch r := schema["required"].(type) {
+	case []string:
+		required = r
+	case []any:
+		for _, name := range r {
+			if s, ok := name.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+	for _, name := range required {
+		if v, ok := values[name]; !ok || v == nil || v == "" {
+			problems = append(problems, fmt.Sprintf("%s is required", fieldTitle(name, props[name])))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		prop, ok := props[name].(map[string]any)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not a field of this form", name))
+			continue
+		}
+		if v := values[name]; v != nil && v != "" {
+			if problem := validateValue(prop, v); problem != "" {
+				problems = append(problems, fmt.Sprintf("%s %s", fieldTitle(name, prop), problem))
+			}
+		}
+	}
+	return problems
+}
+
+// validateValue checks one value against its property schema and describes
+// the problem, or returns "".
+func validateValue(prop map[string]any, v any) string {
+	if enum := enumValues(prop); len(enum) > 0 {
+		if !slices.Contains(enum, v) {
+			return fmt.Sprintf("must be one of %s", joinValues(enum))
+		}
+		return ""
+	}
+	switch prop["type"] {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return "must be text"
+		}
+		if n, ok := number(prop["minLength"]); ok && float64(len([]rune(s))) < n {
+			return fmt.Sprintf("must be at least %v characters", n)
+		}
+		if n, ok := number(prop["maxLength"]); ok && float64(len([]rune(s))) > n {
+			return fmt.Sprintf("must be at most %v characters", n)
+		}
+		return validateFormat(prop["format"], s)
+	case "number", "integer":
+		n, ok := number(v)
+		if !ok {
+			return "must be a number"
+		}
+		if prop["type"] == "integer" && n != math.Trunc(n) {
+			return "must be a whole number"
+		}
+		if min, ok := number(prop["minimum"]); ok && n < min {
+			return fmt.Sprintf("must be at least %v", min)
+		}
+		if max, ok := number(prop["maximum"]); ok && n > max {
+			return fmt.Sprintf("must be at most %v", max)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return "must be true or false"
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return "must be a list"
+		}
+		itemSchema, _ := prop["items"].(map[string]any)
+		enum := enumValues(itemSchema)
+		for _, item := range items {
+			if len(enum) > 0 && !slices.Contains(enum, item) {
+				return fmt.Sprintf("may only contain %s", joinValues(enum))
+			}
+		}
+	}
+	return ""
+}
+
+func validateFormat(format any, s string) string {
+	switch format {
+	case "email":
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be an email address"
+		}
+	case "uri":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			return "must be a URI such as https://example.com"
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return "must be a date like 2025-01-31"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be a date and time like 2025-01-31T09:00:00Z"
+		}
+	}
+	return ""
+}
+
+// enumValues returns the allowed values of an enum schema: "enum", or the
+// "const" of each "oneOf" or "anyOf" option (titled enums).
+func enumValues(prop map[string]any) []any {
+	var values []any
+	switch enum := prop["enum"].(type) {
+	case []string:
+		for _, v := range enum {
+			values = append(values, v)
+		}
+	case []any:
+		values = enum
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		switch options := prop[key].(type) {
+		case []map[string]any:
+			for _, o := range options {
+				values = append(values, o["const"])
+			}
+		case []any:
+			for _, o := range options {
+				if o, ok := o.(map[string]any); ok {
+					values = append(values, o["const"])
+				}
+			}
+		}
+	}
+	return values
+}
+
+func joinValues(values []any) string {
+	var parts []string
+	for _, v := range values {
+		parts = append(parts, fmt.Sprintf("%v", v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// number converts a JSON or Go number to float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// fieldTitle names a field in messages: its title if it has one.
+func fieldTitle(name string, prop any) string {
+	if p, ok := prop.(map[string]any); ok {
+		if title, ok := p["title"].(string); ok && title != "" {
+			return title
+		}
+	}
+	return name
+}
@@ -0,0 +1,186 @@
+package elicit
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type allKinds struct {
+	Name     string   `json:"name" elicit:"title=Name;minLength=1;maxLength=20"`
+	Email    string   `json:"email,omitempty" elicit:"format=email"`
+	Count    int      `json:"count" elicit:"min=1;max=9;default=3"`
+	Ratio    float64  `json:"ratio" elicit:"optional"`
+	Agree    bool     `json:"agree" elicit:"default=true"`
+	Size     string   `json:"size" elicit:"enum=s|m|l"`
+	Color    string   `json:"color" elicit:"enum=r:Red|g:Green"`
+	Tags     []string `json:"tags,omitempty" elicit:"enum=a|b"`
+	Nickname *string  `json:"nickname"`
+	Ignored  string   `json:"-"`
+	private  string
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := SchemaFor[allKinds]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string", "title": "Name", "minLength": 1, "maxLength": 20},
+			"email":    map[string]any{"type": "string", "format": "email"},
+			"count":    map[string]any{"type": "integer", "minimum": 1.0, "maximum": 9.0, "default": int64(3)},
+			"ratio":    map[string]any{"type": "number"},
+			"agree":    map[string]any{"type": "boolean", "default": true},
+			"size":     map[string]any{"type": "string", "enum": []string{"s", "m", "l"}},
+			"color":    map[string]any{"type": "string", "oneOf": []map[string]any{{"const": "r", "title": "Red"}, {"const": "g", "title": "Green"}}},
+			"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string", "enum": []string{"a", "b"}}},
+			"nickname": map[string]any{"type": "string"},
+		},
+		"required": []string{"name", "count", "agree", "size", "color"},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("SchemaFor[allKinds]() =\n%v\nwant\n%v", schema, want)
+	}
+}
+
+func TestSchemaForErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() (map[string]any, error)
+		want string
+	}{
+		{"not a struct", SchemaFor[string], "is not a struct"},
+		{"unsupported type", SchemaFor[struct {
+			M map[string]string `json:"m"`
+		}], "unsupported type"},
+		{"slice without enum", SchemaFor[struct {
+			S []string `json:"s"`
+		}], "need an enum"},
+		{"format on a number", SchemaFor[struct {
+			N int `json:"n" elicit:"format=email"`
+		}], "format is only for strings"},
+		{"unknown format", SchemaFor[struct {
+			S string `json:"s" elicit:"format=phone"`
+		}], "unsupported format"},
+		{"min on a string", SchemaFor[struct {
+			S string `json:"s" elicit:"min=1"`
+		}], "min is only for numbers"},
+		{"bad default", SchemaFor[struct {
+			N int `json:"n" elicit:"default=many"`
+		}], `default="many"`},
+		{"partly titled enum", SchemaFor[struct {
+			S string `json:"s" elicit:"enum=a:A|b"`
+		}], "every value or none"},
+		{"unknown option", SchemaFor[struct {
+			S string `json:"s" elicit:"colour=red"`
+		}], `unknown elicit option "colour"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.fn()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := MustSchemaFor[allKinds]()
+	valid := func(changes map[string]any) map[string]any {
+		values := map[string]any{"name": "Ada", "count": 3.0, "agree": true, "size": "m", "color": "g"}
+		for k, v := range changes {
+			if v == nil {
+				delete(values, k)
+			} else {
+				values[k] = v
+			}
+		}
+		return values
+	}
+	tests := []struct {
+		name   string
+		values map[string]any
+		want   []string
+	}{
+		{"valid", valid(nil), nil},
+		{"all optional fields", valid(map[string]any{"email": "ada@example.com", "ratio": 0.5, "tags": []any{"a", "b"}, "nickname": "A"}), nil},
+		{"missing required", valid(map[string]any{"name": nil, "size": ""}), []string{"Name is required", "size is required"}},
+		{"unknown field", valid(map[string]any{"extra": 1.0}), []string{"extra is not a field of this form"}},
+		{"wrong type", valid(map[string]any{"name": 5.0, "agree": "yes", "count": "3"}), []string{"agree must be true or false", "count must be a number", "Name must be text"}},
+		{"fraction for an integer", valid(map[string]any{"count": 2.5}), []string{"count must be a whole number"}},
+		{"out of range", valid(map[string]any{"count": 10.0}), []string{"count must be at most 9"}},
+		{"too long, in runes", valid(map[string]any{"name": strings.Repeat("é", 21)}), []string{"Name must be at most 20 characters"}},
+		{"not in enum", valid(map[string]any{"size": "xl", "color": "Red"}), []string{"color must be one of r, g", "size must be one of s, m, l"}},
+		{"not in multi-select", valid(map[string]any{"tags": []any{"a", "c"}}), []string{"tags may only contain a, b"}},
+		{"bad email", valid(map[string]any{"email": "Ada <ada@example.com>"}), []string{"email must be an email address"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Validate(schema, tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFormats(t *testing.T) {
+	tests := []struct {
+		format, value string
+		ok            bool
+	}{
+		{"email", "ada@example.com", true},
+		{"email", "ada", false},
+		{"uri", "https://example.com/x", true},
+		{"uri", "example.com", false},
+		{"date", "2025-01-31", true},
+		{"date", "31/01/2025", false},
+		{"date-time", "2025-01-31T09:00:00Z", true},
+		{"date-time", "2025-01-31", false},
+	}
+	for _, tt := range tests {
+		problem := validateFormat(tt.format, tt.value)
+		if (problem == "") != tt.ok {
+			t.Errorf("validateFormat(%s, %q) = %q, want ok: %v", tt.format, tt.value, problem, tt.ok)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type form struct {
+		Name  string `json:"name" elicit:"minLength=2"`
+		Count int    `json:"count" elicit:"min=1"`
+		Note  string `json:"note,omitempty"`
+	}
+	tests := []struct {
+		name    string
+		content map[string]any
+		want    form
+		invalid []string
+	}{
+		{"valid", map[string]any{"name": "Ada", "count": 2.0}, form{Name: "Ada", Count: 2}, nil},
+		{"with optional", map[string]any{"name": "Ada", "count": 2.0, "note": "hi"}, form{Name: "Ada", Count: 2, Note: "hi"}, nil},
+		{"invalid", map[string]any{"name": "A", "count": 0.0}, form{}, []string{"count must be at least 1", "name must be at least 2 characters"}},
+		{"missing", map[string]any{}, form{}, []string{"name is required", "count is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode[form](tt.content)
+			var invalid *ValidationError
+			switch {
+			case tt.invalid == nil && err != nil:
+				t.Fatal(err)
+			case tt.invalid != nil && !errors.As(err, &invalid):
+				t.Fatalf("err = %v, want a *ValidationError", err)
+			case tt.invalid != nil && !reflect.DeepEqual(invalid.Problems, tt.invalid):
+				t.Errorf("problems = %q, want %q", invalid.Problems, tt.invalid)
+			}
+			if got != tt.want {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
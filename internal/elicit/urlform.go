@@ -0,0 +1,236 @@
+package elicit
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// URL-mode elicitation sends the user to a web page instead of showing a
+// form in the client. The client only reports that the user agreed to open
+// the page; the answer arrives out of band, and the server announces it with
+// notifications/elicitation/complete. URLForms is the out-of-band part: it
+// hosts simple forms over HTTP and hands what the user submits to the tool
+// waiting for it.
+
+// ErrFormClosed means a pending form was closed before it was submitted.
+var ErrFormClosed = errors.New("form closed")
+
+// FormField is one input of a hosted form.
+type FormField struct {
+	Name     string
+	Label    string
+	Type     string   // "text" (default), "textarea" or "select"
+	Options  []string // For "select"
+	Required bool
+}
+
+// Form describes a page for the user to fill in.
+type Form struct {
+	Title   string
+	Message string
+	Fields  []FormField
+}
+
+// URLForms hosts forms for URL-mode elicitation. Mount it on an HTTP server
+// at the path of its base URL, with that prefix stripped.
+type URLForms struct {
+	baseURL string
+
+	mu      sync.Mutex
+	pending map[string]*PendingForm
+}
+
+// NewURLForms returns a URLForms whose pages are served under baseURL, for
+// example "http://localhost:3000/elicit/".
+func NewURLForms(baseURL string) *URLForms {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &URLForms{baseURL: baseURL, pending: make(map[string]*PendingForm)}
+}
+
+// PendingForm is a form waiting for the user.
+type PendingForm struct {
+	ID  string // The elicitation ID
+	URL string // Where the user fills the form in
+
+	forms  *URLForms
+	form   Form
+	done   chan struct{}
+	values map[string]string // Set when done is closed
+}
+
+// Open registers form under a new, unguessable elicitation ID. Close the
+// pending form when it is no longer wanted.
+func (f *URLForms) Open(form Form) *PendingForm {
+	id := rand.Text()
+	p := &PendingForm{
+		ID:    id,
+		URL:   f.baseURL + id,
+		forms: f,
+		form:  form,
+		done:  make(chan struct{}),
+	}
+	f.mu.Lock()
+	f.pending[id] = p
+	f.mu.Unlock()
+	return p
+}
+
+// Wait returns the submitted values once the user submits the form.
+func (p *PendingForm) Wait(ctx context.Context) (map[string]string, error) {
+	select {
+	case <-p.done:
+		if p.values == nil {
+			return nil, ErrFormClosed
+		}
+		return p.values, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close withdraws the form. Its page stops being served.
+func (p *PendingForm) Close() {
+	p.forms.mu.Lock()
+	defer p.forms.mu.Unlock()
+	if p.forms.pending[p.ID] == p {
+		delete(p.forms.pending, p.ID)
+		if p.values == nil {
+			close(p.done)
+		}
+	}
+}
+
+// ServeHTTP shows the form whose ID is the request path (GET) and accepts
+// its submission (POST).
+func (f *URLForms) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/")
+	f.mu.Lock()
+	p := f.pending[id]
+	f.mu.Unlock()
+	if p == nil {
+		w.WriteHeader(http.StatusNotFound)
+		render(w, page{Title: "Form not found", Notice: "This form has expired, was already submitted, or never existed."})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		render(w, page{Title: p.form.Title, Message: p.form.Message, Fields: p.form.Fields})
+	case http.MethodPost:
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		values, problems := p.form.values(r.PostForm)
+		if len(problems) > 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			render(w, page{Title: p.form.Title, Message: p.form.Message, Fields: p.form.Fields, Values: values, Problems: problems})
+			return
+		}
+		if !f.submit(p, values) {
+			w.WriteHeader(http.StatusNotFound)
+			render(w, page{Title: "Form not found", Notice: "This form has expired or was already submitted."})
+			return
+		}
+		render(w, page{Title: "Thank you", Notice: "Your answer was sent. You can close this window."})
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// submit hands values to p's waiter and withdraws p. It reports false if p
+// was closed or submitted meanwhile.
+func (f *URLForms) submit(p *PendingForm, values map[string]string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.pending[p.ID] != p {
+		return false
+	}
+	delete(f.pending, p.ID)
+	p.values = values
+	close(p.done)
+	return true
+}
+
+// values picks the form's fields out of posted data and checks them.
+func (form Form) values(posted map[string][]string) (map[string]string, []string) {
+	values := make(map[string]string)
+	var problems []string
+	for _, field := range form.Fields {
+		var v string
+		if vs := posted[field.Name]; len(vs) > 0 {
+			v = strings.TrimSpace(vs[0])
+		}
+		values[field.Name] = v
+		switch {
+		case v == "" && field.Required:
+			problems = append(problems, field.Label+" is required")
+		case v != "" && field.Type == "select" && !slices.Contains(field.Options, v):
+			problems = append(problems, field.Label+" must be one of "+strings.Join(field.Options, ", "))
+		}
+	}
+	return values, problems
+}
+
+type page struct {
+	Title    string
+	Message  string
+	Notice   string
+	Fields   []FormField
+	Values   map[string]string
+	Problems []string
+}
+
+func render(w http.ResponseWriter, p page) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = pageTemplate.Execute(w, p)
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 36rem; margin: 3rem auto; padding: 0 1rem; }
+label { display: block; margin-top: 1rem; font-weight: 600; }
+input, select, textarea { width: 100%; padding: .4rem; margin-top: .3rem; box-sizing: border-box; }
+textarea { min-height: 6rem; }
+button { margin-top: 1.5rem; padding: .5rem 1.5rem; }
+.problems { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Notice}}<p>{{.}}</p>{{end}}
+{{with .Message}}<p>{{.}}</p>{{end}}
+{{with .Problems}}<ul class="problems">{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Fields}}<form method="post">
+{{- $values := .Values}}
+{{range .Fields}}<label for="{{.Name}}">{{.Label}}{{if .Required}} *{{end}}</label>
+{{- if eq .Type "textarea"}}
+<textarea id="{{.Name}}" name="{{.Name}}"{{if .Required}} required{{end}}>{{index $values .Name}}</textarea>
+{{- else if eq .Type "select"}}
+<select id="{{.Name}}" name="{{.Name}}"{{if .Required}} required{{end}}>
+<option value=""></option>
+{{- $value := index $values .Name}}
+{{range .Options}}<option{{if eq . $value}} selected{{end}}>{{.}}</option>
+{{end}}</select>
+{{- else}}
+<input id="{{.Name}}" name="{{.Name}}" value="{{index $values .Name}}"{{if .Required}} required{{end}}>
+{{- end}}
+{{end}}<button type="submit">Submit</button>
+</form>{{end}}
+</body>
+</html>
+`))
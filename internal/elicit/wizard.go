@@ -0,0 +1,258 @@
+// Package elicit builds multi-step elicitation flows.
+//
+// WHY A WIZARD?
+// A single elicitation/create request shows one flat form and returns
+// whatever the user submitted. Real flows often need several pages, answers
+// that depend on earlier ones, and checks a JSON schema can't express ("the
+// start date can't be in the past"). A Wizard chains steps, validates each
+// response against its schema and the step's own rules, re-prompts with an
+// explanation when something is wrong, lets the user go back a step, and
+// finally decodes all the answers into a Go value for the calling tool.
+//
+// TYPED FORMS:
+// Instead of hand-writing a RequestedSchema and picking values out of the
+// accepted Content with type assertions, describe the form as a struct and
+// let SchemaFor and Decode do both directions:
+//
+//	type confirmForm struct {
+//		Confirm bool   `json:"confirm" elicit:"title=Confirm"`
+//		Reason  string `json:"reason,omitempty" elicit:"title=Reason;maxLength=200"`
+//	}
+//
+//	form, err := elicit.Ask[confirmForm](ctx, req.Session, "Please confirm")
+//
+// Field names come from the json tag. A field is required unless its json
+// tag has omitempty, it is a pointer, or its elicit tag says optional. The
+// elicit tag holds ";"-separated options:
+//
+//	optional                 the user may leave the field out
+//	title=Start date         title shown in the form
+//	description=...          help text
+//	format=date              strings: email, uri, date or date-time
+//	minLength=1, maxLength=200
+//	min=1, max=14            numbers
+//	default=3                parsed as the field's type; "|"-separated for []string
+//	enum=small|medium|large  allowed values (strings and []string)
+//	enum=c:Celsius|f:Fahrenheit  allowed values with display titles
+//
+// Supported field types are string, bool, the integer and float types, and
+// []string (a multi-select, which needs an enum), or pointers to them.
+//
+// URL FORMS:
+// URLForms hosts pages for URL-mode elicitation on the server's own HTTP
+// endpoint and hands what the user submits back to the waiting tool.
+package elicit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// BackField is the boolean field added to every step after the first. An
+// accepted response with it set returns to the previous step.
+const BackField = "goBack"
+
+// DefaultMaxAttempts is how many invalid responses a step accepts before the
+// wizard gives up.
+const DefaultMaxAttempts = 3
+
+var (
+	// ErrDeclined means the user declined a step.
+	ErrDeclined = errors.New("user declined")
+	// ErrCancelled means the user dismissed a step without choosing.
+	ErrCancelled = errors.New("user cancelled")
+	// ErrTooManyAttempts means a step got too many invalid responses.
+	ErrTooManyAttempts = errors.New("too many invalid responses")
+)
+
+// Session is the part of *mcp.ServerSession a wizard uses. Elicit may report
+// accepted content that doesn't match the requested schema as a
+// *ValidationError; the wizard then asks again. (*mcp.ServerSession's own
+// error for this can't be told apart from a failed request.)
+type Session interface {
+	Elicit(context.Context, *mcp.ElicitParams) (*mcp.ElicitResult, error)
+}
+
+// Answers holds the accepted content of each completed step, by step name.
+type Answers map[string]map[string]any
+
+// Decode merges the answers of all steps, in order, and decodes them into v
+// as JSON. Later steps win if two steps share a field name.
+func (a Answers) Decode(v any, steps []Step) error {
+	merged := make(map[string]any)
+	for _, step := range steps {
+		maps.Copy(merged, a[step.Name])
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Step is one form of a wizard.
+type Step struct {
+	Name    string
+	Message string
+	// Summary, if set, is appended to Message; use it to recap earlier
+	// answers, e.g. on a final confirmation step.
+	Summary func(Answers) string
+	// Schema is a flat elicitation schema: an object whose properties are
+	// strings, numbers, integers, booleans or enums.
+	Schema map[string]any
+	// Check, if set, applies rules the schema can't express. It sees this
+	// step's values (already valid against Schema) and the earlier answers.
+	// Its error is shown to the user, who is asked again.
+	Check func(values map[string]any, answers Answers) error
+}
+
+// Wizard runs Steps in order.
+type Wizard struct {
+	Title       string // Shown before each step's message, with the step number
+	Steps       []Step
+	MaxAttempts int // Per visit to a step; 0 means DefaultMaxAttempts
+}
+
+// Run walks the user through the steps and returns their answers. It returns
+// ErrDeclined or ErrCancelled if the user backs out, ErrTooManyAttempts
+// (wrapped) if a step is never answered validly, and the session's error if
+// elicitation fails.
+func (w *Wizard) Run(ctx context.Context, s Session) (Answers, error) {
+	maxAttempts := w.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	answers := make(Answers)
+	for i := 0; i < len(w.Steps); {
+		values, back, err := w.ask(ctx, s, i, answers, maxAttempts)
+		if err != nil {
+			return answers, err
+		}
+		if back {
+			i--
+			continue
+		}
+		answers[w.Steps[i].Name] = values
+		i++
+	}
+	return answers, nil
+}
+
+// ask shows step i until the user answers it validly or asks to go back.
+func (w *Wizard) ask(ctx context.Context, s Session, i int, answers Answers, maxAttempts int) (values map[string]any, back bool, err error) {
+	step := w.Steps[i]
+	canGoBack := i > 0
+	message := w.message(i, answers)
+	problem := ""
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		prompt := message
+		if problem != "" {
+			prompt = fmt.Sprintf("%s\n\n⚠️ Please fix: %s", message, problem)
+		}
+		result, err := s.Elicit(ctx, &mcp.ElicitParams{
+			Message:         prompt,
+			RequestedSchema: requestSchema(step.Schema, answers[step.Name], canGoBack),
+		})
+		if err != nil {
+			// Content that doesn't match the schema is the user's mistake,
+			// so ask again.
+			var invalid *ValidationError
+			if errors.As(err, &invalid) {
+				problem = strings.Join(invalid.Problems, "; ")
+				continue
+			}
+			return nil, false, err
+		}
+
+		switch result.Action {
+		case "decline":
+			return nil, false, ErrDeclined
+		case "cancel":
+			return nil, false, ErrCancelled
+		}
+
+		values := maps.Clone(result.Content)
+		if values == nil {
+			values = map[string]any{}
+		}
+		if canGoBack && values[BackField] == true {
+			return nil, true, nil
+		}
+		delete(values, BackField)
+		if problems := Validate(step.Schema, values); len(problems) > 0 {
+			problem = strings.Join(problems, "; ")
+			continue
+		}
+		if step.Check != nil {
+			if err := step.Check(values, answers); err != nil {
+				problem = err.Error()
+				continue
+			}
+		}
+		return values, false, nil
+	}
+	return nil, false, fmt.Errorf("step %q: %w: %s", step.Name, ErrTooManyAttempts, problem)
+}
+
+// Run runs w and decodes the answers into a T.
+func Run[T any](ctx context.Context, s Session, w *Wizard) (T, error) {
+	var v T
+	answers, err := w.Run(ctx, s)
+	if err != nil {
+		return v, err
+	}
+	err = answers.Decode(&v, w.Steps)
+	return v, err
+}
+
+// message is the text shown for step i.
+func (w *Wizard) message(i int, answers Answers) string {
+	step := w.Steps[i]
+	var b strings.Builder
+	if w.Title != "" {
+		fmt.Fprintf(&b, "%s (step %d of %d)\n\n", w.Title, i+1, len(w.Steps))
+	}
+	b.WriteString(step.Message)
+	if step.Summary != nil {
+		fmt.Fprintf(&b, "\n\n%s", step.Summary(answers))
+	}
+	return b.String()
+}
+
+// requestSchema is the schema sent for a step. Earlier answers to the step
+// become defaults, so going back shows what the user entered. Steps that can
+// go back get BackField and drop "required", so the user can leave without
+// filling the form in; Validate enforces "required" instead.
+func requestSchema(schema map[string]any, previous map[string]any, canGoBack bool) map[string]any {
+	out := maps.Clone(schema)
+	props, _ := schema["properties"].(map[string]any)
+	props = maps.Clone(props)
+	if props == nil {
+		props = map[string]any{}
+	}
+	for name, value := range previous {
+		if prop, ok := props[name].(map[string]any); ok {
+			prop = maps.Clone(prop)
+			prop["default"] = value
+			props[name] = prop
+		}
+	}
+	if canGoBack {
+		props[BackField] = map[string]any{
+			"type":        "boolean",
+			"title":       "Go back",
+			"description": "Return to the previous step instead",
+			"default":     false,
+		}
+		delete(out, "required")
+	}
+	out["properties"] = props
+	return out
+}
@@ -0,0 +1,115 @@
+package elicit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// reply is one scripted answer to an elicitation.
+type reply struct {
+	result *mcp.ElicitResult
+	err    error
+}
+
+// scripted answers elicitations in order and records their messages.
+type scripted struct {
+	replies  []reply
+	messages []string
+}
+
+func (s *scripted) Elicit(_ context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	s.messages = append(s.messages, params.Message)
+	if len(s.replies) == 0 {
+		return nil, errors.New("no more replies")
+	}
+	r := s.replies[0]
+	s.replies = s.replies[1:]
+	return r.result, r.err
+}
+
+func accept(content map[string]any) reply {
+	return reply{result: &mcp.ElicitResult{Action: "accept", Content: content}}
+}
+
+type nameForm struct {
+	Name string `json:"name" elicit:"title=Name;minLength=2"`
+}
+
+type ageForm struct {
+	Age int `json:"age" elicit:"title=Age;min=0;max=150"`
+}
+
+func twoSteps() *Wizard {
+	return &Wizard{Steps: []Step{
+		{Name: "name", Message: "Who are you?", Schema: MustSchemaFor[nameForm]()},
+		{Name: "age", Message: "How old are you?", Schema: MustSchemaFor[ageForm]()},
+	}}
+}
+
+func TestWizardRepromptsOnValidationError(t *testing.T) {
+	s := &scripted{replies: []reply{
+		{err: &ValidationError{Problems: []string{"Name must be a string"}}},
+		accept(map[string]any{"name": "x"}), // Too short
+		accept(map[string]any{"name": "Ada"}),
+		accept(map[string]any{"age": 36.0}),
+	}}
+	answers, err := twoSteps().Run(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answers["name"]["name"] != "Ada" || answers["age"]["age"] != 36.0 {
+		t.Errorf("answers = %v", answers)
+	}
+	if len(s.messages) != 4 {
+		t.Fatalf("asked %d times, want 4", len(s.messages))
+	}
+	if !strings.Contains(s.messages[1], "Please fix: Name must be a string") {
+		t.Errorf("second prompt = %q, want it to repeat the session's problem", s.messages[1])
+	}
+	if !strings.Contains(s.messages[2], "Please fix: Name must be at least 2 characters") {
+		t.Errorf("third prompt = %q, want it to name the short name", s.messages[2])
+	}
+}
+
+func TestWizardErrors(t *testing.T) {
+	failure := errors.New("connection lost")
+	invalid := reply{err: &ValidationError{Problems: []string{"bad"}}}
+	tests := []struct {
+		name    string
+		replies []reply
+		want    error
+	}{
+		{"session error", []reply{{err: failure}}, failure},
+		{"declined", []reply{{result: &mcp.ElicitResult{Action: "decline"}}}, ErrDeclined},
+		{"cancelled", []reply{accept(map[string]any{"name": "Ada"}), {result: &mcp.ElicitResult{Action: "cancel"}}}, ErrCancelled},
+		{"too many attempts", []reply{invalid, invalid, invalid}, ErrTooManyAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := twoSteps().Run(context.Background(), &scripted{replies: tt.replies})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWizardGoesBack(t *testing.T) {
+	s := &scripted{replies: []reply{
+		accept(map[string]any{"name": "Ada"}),
+		accept(map[string]any{BackField: true}),
+		accept(map[string]any{"name": "Grace"}),
+		accept(map[string]any{"age": 85.0}),
+	}}
+	answers, err := twoSteps().Run(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answers["name"]["name"] != "Grace" {
+		t.Errorf("name = %v after going back, want Grace", answers["name"]["name"])
+	}
+}
@@ -0,0 +1,511 @@
+// Package expr parses and evaluates arithmetic expressions.
+//
+// It backs the bonus_calculator tool. The grammar, from lowest to highest
+// precedence:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = ("-" | "+") unary | power
+//	power   = primary [ "^" unary ]          (right-associative)
+//	primary = number | name | name "(" [ expr { "," expr } ] ")" | "(" expr ")"
+//
+// so -2^2 is -(2^2) and 2^3^2 is 2^(3^2), as in mathematics. Names are
+// constants (pi, e), functions (see Functions) or caller-supplied variables.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Node is a parsed expression.
+type Node interface {
+	// Eval computes the node's value. vars supplies variable values.
+	Eval(vars map[string]float64) (float64, error)
+	// String renders the node fully parenthesised, showing how it parsed.
+	String() string
+}
+
+// SyntaxError reports malformed input. Pos is a 0-based byte offset.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// Constants are the names always defined.
+var Constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// function describes a built-in function. maxArgs < 0 means variadic.
+type function struct {
+	minArgs, maxArgs int
+	fn               func(args []float64) (float64, error)
+}
+
+func unary(f func(float64) float64) function {
+	return function{1, 1, func(a []float64) (float64, error) { return f(a[0]), nil }}
+}
+
+var functions = map[string]function{
+	"sqrt":  unary(math.Sqrt),
+	"abs":   unary(math.Abs),
+	"exp":   unary(math.Exp),
+	"ln":    unary(math.Log),
+	"log10": unary(math.Log10),
+	"log2":  unary(math.Log2),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"round": unary(math.Round),
+	"pow": {2, 2, func(a []float64) (float64, error) {
+		return math.Pow(a[0], a[1]), nil
+	}},
+	"atan2": {2, 2, func(a []float64) (float64, error) {
+		return math.Atan2(a[0], a[1]), nil
+	}},
+	// log(x) is the natural logarithm; log(x, b) is the logarithm base b.
+	"log": {1, 2, func(a []float64) (float64, error) {
+		if len(a) == 1 {
+			return math.Log(a[0]), nil
+		}
+		return math.Log(a[0]) / math.Log(a[1]), nil
+	}},
+	"min": {1, -1, func(a []float64) (float64, error) { return slices.Min(a), nil }},
+	"max": {1, -1, func(a []float64) (float64, error) { return slices.Max(a), nil }},
+}
+
+// Functions returns the names of the built-in functions, sorted.
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ValidateVariables checks that every variable name is a valid identifier
+// that doesn't shadow a constant or function.
+func ValidateVariables(vars map[string]float64) error {
+	for name := range vars {
+		if !isIdent(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+		if _, ok := Constants[name]; ok {
+			return fmt.Errorf("variable %q shadows a constant", name)
+		}
+		if _, ok := functions[name]; ok {
+			return fmt.Errorf("variable %q shadows a function", name)
+		}
+	}
+	return nil
+}
+
+// Evaluate parses s and evaluates it with vars. The result must be a
+// finite number.
+func Evaluate(s string, vars map[string]float64) (Node, float64, error) {
+	if err := ValidateVariables(vars); err != nil {
+		return nil, 0, err
+	}
+	n, err := Parse(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	v, err := n.Eval(vars)
+	if err != nil {
+		return n, 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return n, 0, fmt.Errorf("result is not a finite number (%v)", v)
+	}
+	return n, v, nil
+}
+
+// =============================================================================
+// AST
+// =============================================================================
+
+type number float64
+
+func (n number) Eval(map[string]float64) (float64, error) { return float64(n), nil }
+func (n number) String() string                           { return strconv.FormatFloat(float64(n), 'g', -1, 64) }
+
+type name string
+
+func (n name) Eval(vars map[string]float64) (float64, error) {
+	if v, ok := vars[string(n)]; ok {
+		return v, nil
+	}
+	if v, ok := Constants[string(n)]; ok {
+		return v, nil
+	}
+	if _, ok := functions[string(n)]; ok {
+		return 0, fmt.Errorf("%s is a function; call it as %s(...)", n, n)
+	}
+	return 0, fmt.Errorf("undefined variable %q", string(n))
+}
+
+func (n name) String() string { return string(n) }
+
+type negate struct{ x Node }
+
+func (n negate) Eval(vars map[string]float64) (float64, error) {
+	x, err := n.x.Eval(vars)
+	return -x, err
+}
+
+func (n negate) String() string { return "(-" + n.x.String() + ")" }
+
+type binary struct {
+	op   byte
+	l, r Node
+}
+
+func (b binary) Eval(vars map[string]float64) (float64, error) {
+	l, err := b.l.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	r, err := b.r.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch b.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero in %s", b)
+		}
+		return l / r, nil
+	case '%':
+		if r == 0 {
+			return 0, fmt.Errorf("modulo by zero in %s", b)
+		}
+		return math.Mod(l, r), nil
+	case '^':
+		return math.Pow(l, r), nil
+	}
+	return 0, fmt.Errorf("unknown operator %q", b.op)
+}
+
+func (b binary) String() string {
+	return "(" + b.l.String() + " " + string(b.op) + " " + b.r.String() + ")"
+}
+
+type call struct {
+	fn   string
+	args []Node
+}
+
+func (c call) Eval(vars map[string]float64) (float64, error) {
+	f := functions[c.fn]
+	args := make([]float64, len(c.args))
+	for i, a := range c.args {
+		v, err := a.Eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	return f.fn(args)
+}
+
+func (c call) String() string {
+	args := make([]string, len(c.args))
+	for i, a := range c.args {
+		args[i] = a.String()
+	}
+	return c.fn + "(" + strings.Join(args, ", ") + ")"
+}
+
+// =============================================================================
+// Parser
+// =============================================================================
+
+// Parse parses s into an expression tree. Function names and argument
+// counts are checked here; variables are resolved by Eval.
+func Parse(s string) (Node, error) {
+	p := &parser{src: s}
+	p.next()
+	if p.tok.kind == tokEOF {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty expression"}
+	}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return n, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokName
+	tokOp // one of + - * / % ^ ( ) ,
+	tokInvalid
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string
+	num  float64
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokInvalid:
+		if utf8.RuneCountInString(t.text) > 1 {
+			return fmt.Sprintf("invalid number %q", t.text)
+		}
+		return fmt.Sprintf("character %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next advances to the next token.
+func (p *parser) next() {
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		p.pos += size
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case isDigit(c) || c == '.':
+		for p.pos < len(p.src) && (isDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// Exponent, e.g. 1.5e-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			i := p.pos + 1
+			if i < len(p.src) && (p.src[i] == '+' || p.src[i] == '-') {
+				i++
+			}
+			if i < len(p.src) && isDigit(p.src[i]) {
+				for i < len(p.src) && isDigit(p.src[i]) {
+					i++
+				}
+				p.pos = i
+			}
+		}
+		text := p.src[start:p.pos]
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			p.tok = token{kind: tokInvalid, pos: start, text: text}
+			return
+		}
+		p.tok = token{kind: tokNumber, pos: start, text: text, num: v}
+	case isLetter(c):
+		for p.pos < len(p.src) && (isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokName, pos: start, text: p.src[start:p.pos]}
+	case strings.IndexByte("+-*/%^(),", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokOp, pos: start, text: string(c)}
+	default:
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.pos += size
+		p.tok = token{kind: tokInvalid, pos: start, text: string(r)}
+	}
+}
+
+func (p *parser) isOp(ops string) bool {
+	return p.tok.kind == tokOp && strings.Contains(ops, p.tok.text)
+}
+
+func (p *parser) expr() (Node, error) {
+	l, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+-") {
+		op := p.tok.text[0]
+		p.next()
+		r, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op, l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) term() (Node, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*/%") {
+		op := p.tok.text[0]
+		p.next()
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op, l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) unary() (Node, error) {
+	if p.isOp("-+") {
+		op := p.tok.text
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "-" {
+			return negate{x}, nil
+		}
+		return x, nil
+	}
+	return p.power()
+}
+
+func (p *parser) power() (Node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") {
+		p.next()
+		exp, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return binary{'^', base, exp}, nil
+	}
+	return base, nil
+}
+
+func (p *parser) primary() (Node, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokNumber:
+		p.next()
+		return number(tok.num), nil
+
+	case tok.kind == tokName:
+		p.next()
+		if !p.isOp("(") {
+			return name(tok.text), nil
+		}
+		f, ok := functions[tok.text]
+		if !ok {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unknown function %q", tok.text)}
+		}
+		p.next()
+		var args []Node
+		if !p.isOp(")") {
+			for {
+				arg, err := p.expr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+		}
+		if !p.isOp(")") {
+			return nil, p.errorf("expected \",\" or \")\" in call to %s, found %s", tok.text, p.tok)
+		}
+		p.next()
+		if len(args) < f.minArgs || (f.maxArgs >= 0 && len(args) > f.maxArgs) {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("%s takes %s, got %d", tok.text, arity(f), len(args))}
+		}
+		return call{tok.text, args}, nil
+
+	case p.isOp("("):
+		p.next()
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOp(")") {
+			return nil, p.errorf("expected \")\", found %s", p.tok)
+		}
+		p.next()
+		return n, nil
+	}
+	return nil, p.errorf("expected a number, name or \"(\", found %s", tok)
+}
+
+func arity(f function) string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.minArgs)
+	case f.minArgs == f.maxArgs && f.minArgs == 1:
+		return "1 argument"
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+	}
+}
+
+func isDigit(c byte) bool  { return '0' <= c && c <= '9' }
+func isLetter(c byte) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_' }
+
+func isIdent(s string) bool {
+	if s == "" || !isLetter(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isLetter(s[i]) && !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
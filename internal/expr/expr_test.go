@@ -0,0 +1,90 @@
+package expr
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		in     string
+		vars   map[string]float64
+		parsed string
+		want   float64
+	}{
+		{in: "1 + 2 * 3", parsed: "(1 + (2 * 3))", want: 7},
+		{in: "(1 + 2) * 3", parsed: "((1 + 2) * 3)", want: 9},
+		{in: "10 - 4 - 3", parsed: "((10 - 4) - 3)", want: 3},
+		{in: "12 / 3 / 2", parsed: "((12 / 3) / 2)", want: 2},
+		{in: "7 % 4 * 2", parsed: "((7 % 4) * 2)", want: 6},
+		{in: "2^3^2", parsed: "(2 ^ (3 ^ 2))", want: 512},
+		{in: "-2^2", parsed: "(-(2 ^ 2))", want: -4},
+		{in: "2^-1", parsed: "(2 ^ (-1))", want: 0.5},
+		{in: "2 * 3^2", parsed: "(2 * (3 ^ 2))", want: 18},
+		{in: "1.5e2 + .5", parsed: "(150 + 0.5)", want: 150.5},
+		{in: "x * y + 1", vars: map[string]float64{"x": 2, "y": 4}, parsed: "((x * y) + 1)", want: 9},
+		{in: "max(1, 5, 3) + min(4, 2)", parsed: "(max(1, 5, 3) + min(4, 2))", want: 7},
+		{in: "log(8, 2)", parsed: "log(8, 2)", want: 3},
+		{in: "cos(pi)", parsed: "cos(pi)", want: -1},
+		{in: " 1　+\t2\n", parsed: "(1 + 2)", want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			n, got, err := Evaluate(tt.in, tt.vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.String() != tt.parsed {
+				t.Errorf("parsed as %s, want %s", n, tt.parsed)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("= %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		vars map[string]float64
+		want string
+		pos  int // Byte offset of a SyntaxError, or -1 for an evaluation error
+	}{
+		{in: "", want: "empty expression", pos: 0},
+		{in: "1 +", want: "found end of expression", pos: 3},
+		{in: "(1 + 2", want: `expected ")"`, pos: 6},
+		{in: "1 2", want: `unexpected "2"`, pos: 2},
+		{in: "1.2.3", want: `invalid number "1.2.3"`, pos: 0},
+		{in: "2 × 3", want: `character "×"`, pos: 2},
+		{in: "é", want: `character "é"`, pos: 0},
+		{in: "nope(1)", want: `unknown function "nope"`, pos: 0},
+		{in: "sqrt(1, 2)", want: "sqrt takes 1 argument, got 2", pos: 0},
+		{in: "x + 1", want: `undefined variable "x"`, pos: -1},
+		{in: "sqrt + 1", want: "sqrt is a function", pos: -1},
+		{in: "1 / (2 - 2)", want: "division by zero", pos: -1},
+		{in: "5 % 0", want: "modulo by zero", pos: -1},
+		{in: "sqrt(-1)", want: "not a finite number", pos: -1},
+		{in: "x", vars: map[string]float64{"pi": 3}, want: `variable "pi" shadows a constant`, pos: -1},
+		{in: "x", vars: map[string]float64{"1x": 3}, want: `invalid variable name "1x"`, pos: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, _, err := Evaluate(tt.in, tt.vars)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.want)
+			}
+			var syntax *SyntaxError
+			if isSyntax := errors.As(err, &syntax); isSyntax != (tt.pos >= 0) {
+				t.Errorf("err = %T, want a SyntaxError: %v", err, tt.pos >= 0)
+			} else if isSyntax && syntax.Pos != tt.pos {
+				t.Errorf("error position = %d, want %d", syntax.Pos, tt.pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package gateway aggregates several upstream MCP servers into this one.
+//
+// WHAT IS A GATEWAY?
+// In gateway mode the server also acts as an MCP *client*: it connects to
+// each upstream server declared in a JSON config file and re-exposes their
+// tools, resources and prompts alongside the built-in ones. Upstream names
+// are namespaced by the upstream's configured name (see package bridge), so
+// "get_weather" from an upstream called "weather" appears as
+// "weather__get_weather". When an upstream's lists change, the gateway
+// re-syncs and the server notifies its own clients.
+//
+// Example config:
+//
+//	{
+//	  "upstreams": [
+//	    {"name": "local", "command": "go", "args": ["run", "./cmd/stdio"]},
+//	    {"name": "remote", "url": "http://localhost:3000/mcp"}
+//	  ]
+//	}
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/bridge"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ConfigEnv is the environment variable naming the gateway config file.
+const ConfigEnv = "MCP_GATEWAY_CONFIG"
+
+// Config lists the upstream servers to aggregate.
+type Config struct {
+	Upstreams []Upstream `json:"upstreams"`
+}
+
+// Upstream describes one upstream server: either a stdio command or a
+// streamable-HTTP URL.
+type Upstream struct {
+	// Name namespaces everything this upstream exposes.
+	Name string `json:"name"`
+
+	// Command, Args and Env launch a stdio server. Env is added to the
+	// gateway's own environment.
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+
+	// URL connects to a streamable-HTTP server.
+	URL string `json:"url,omitempty"`
+}
+
+// Names must be safe to embed in tool names, which LLM APIs restrict.
+var validName = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// ConfigFromEnv loads the config named by MCP_GATEWAY_CONFIG. It returns
+// nil, nil when the variable is unset, i.e. gateway mode is off.
+func ConfigFromEnv() (*Config, error) {
+	path := os.Getenv(ConfigEnv)
+	if path == "" {
+		return nil, nil
+	}
+	return LoadConfig(path)
+}
+
+// LoadConfig reads and validates a gateway config file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks that every upstream has a unique, valid name and exactly
+// one of Command or URL.
+func (c *Config) Validate() error {
+	seen := make(map[string]bool)
+	for i, u := range c.Upstreams {
+		if !validName.MatchString(u.Name) {
+			return fmt.Errorf("upstream %d: name %q must be non-empty and contain only letters, digits and '-'", i, u.Name)
+		}
+		if seen[u.Name] {
+			return fmt.Errorf("upstream %d: duplicate name %q", i, u.Name)
+		}
+		seen[u.Name] = true
+		if (u.Command == "") == (u.URL == "") {
+			return fmt.Errorf("upstream %q: exactly one of command or url is required", u.Name)
+		}
+	}
+	return nil
+}
+
+// transport returns a fresh transport for the upstream.
+func (u *Upstream) transport() mcp.Transport {
+	if u.URL != "" {
+		return &mcp.StreamableClientTransport{Endpoint: u.URL}
+	}
+	cmd := exec.Command(u.Command, u.Args...)
+	cmd.Stderr = os.Stderr
+	// Upstreams never inherit the gateway config, so a gateway can safely
+	// launch this same server as an upstream without recursing.
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, ConfigEnv+"=") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	for k, v := range u.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	return &mcp.CommandTransport{Command: cmd}
+}
+
+// Gateway holds the connections to all upstreams of one server.
+type Gateway struct {
+	proxies []*bridge.Proxy
+}
+
+// Attach mirrors every upstream in cfg onto s, which must not have a
+// session yet. Like a bridge.Proxy, a Gateway serves a single session;
+// create one per server (e.g. per HTTP session). It connects to the
+// upstreams when the client initializes, and if any of them can't be
+// reached the client's initialize fails.
+func Attach(s *mcp.Server, cfg *Config) *Gateway {
+	return attach(s, cfg, (*Upstream).transport)
+}
+
+// attach is Attach with the transport for each upstream made by transport.
+func attach(s *mcp.Server, cfg *Config, transport func(*Upstream) mcp.Transport) *Gateway {
+	g := &Gateway{}
+	for i := range cfg.Upstreams {
+		u := &cfg.Upstreams[i]
+		g.proxies = append(g.proxies, bridge.Attach(s, transport(u), u.Name))
+	}
+	return g
+}
+
+// Close disconnects from all upstreams.
+func (g *Gateway) Close() error {
+	var errs []error
+	for _, p := range g.proxies {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
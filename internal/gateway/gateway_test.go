@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/server"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectGateway attaches an upstream server.NewServer for each name to a
+// server.NewServer, and returns a client of the latter.
+func connectGateway(t *testing.T, names ...string) *mcp.ClientSession {
+	t.Helper()
+	upstreams := make(map[string]*mcp.Server)
+	for _, name := range names {
+		upstreams[name] = server.NewServer()
+	}
+	return connectUpstreams(t, upstreams, nil)
+}
+
+// connectUpstreams attaches the upstreams, by name, to a server.NewServer
+// over in-memory transports, and connects a client with opts to it.
+func connectUpstreams(t *testing.T, upstreams map[string]*mcp.Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+
+	cfg := &Config{}
+	transports := make(map[string]mcp.Transport)
+	for _, name := range slices.Sorted(maps.Keys(upstreams)) {
+		clientTransport, serverTransport := mcp.NewInMemoryTransports()
+		ss, err := upstreams[name].Connect(ctx, serverTransport, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { ss.Close() })
+		cfg.Upstreams = append(cfg.Upstreams, Upstream{Name: name})
+		transports[name] = clientTransport
+	}
+
+	s := server.NewServer()
+	g := attach(s, cfg, func(u *Upstream) mcp.Transport { return transports[u.Name] })
+	t.Cleanup(func() { g.Close() })
+
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, opts).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func TestGatewayNamespaces(t *testing.T) {
+	ctx := context.Background()
+	cs := connectGateway(t, "a", "b")
+
+	var tools []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		tools = append(tools, tool.Name)
+	}
+	var prompts []string
+	for p, err := range cs.Prompts(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		prompts = append(prompts, p.Name)
+	}
+	var resources []string
+	for r, err := range cs.Resources(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		resources = append(resources, r.URI)
+	}
+	var templates []string
+	for rt, err := range cs.ResourceTemplates(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		templates = append(templates, rt.URITemplate)
+	}
+
+	for _, ns := range []string{"a", "b"} {
+		if !slices.Contains(tools, ns+"__hello") {
+			t.Errorf("tools = %v, want %s__hello", tools, ns)
+		}
+		if !slices.Contains(prompts, ns+"__greet") {
+			t.Errorf("prompts = %v, want %s__greet", prompts, ns)
+		}
+		if !slices.Contains(resources, ns+"+about://server") {
+			t.Errorf("resources = %v, want %s+about://server", resources, ns)
+		}
+		if !slices.Contains(templates, ns+"+item://{id}") {
+			t.Errorf("resource templates = %v, want %s+item://{id}", templates, ns)
+		}
+	}
+	// The gateway's own tools are still there.
+	if !slices.Contains(tools, "hello") {
+		t.Errorf("tools = %v, want the built-in hello too", tools)
+	}
+}
+
+func TestGatewayForwardsCalls(t *testing.T) {
+	ctx := context.Background()
+	cs := connectGateway(t, "a", "b")
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "b__hello", Arguments: map[string]any{"name": "Ada"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError || len(res.Content) == 0 {
+		t.Fatalf("b__hello = %+v, want a greeting", res)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; text != "Hello, Ada! Welcome to MCP." {
+		t.Errorf("b__hello text = %q", text)
+	}
+
+	prompt, err := cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: "a__greet", Arguments: map[string]string{"name": "Ada"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prompt.Messages) == 0 {
+		t.Error("a__greet returned no messages")
+	}
+
+	read, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "a+about://server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Contents) != 1 || read.Contents[0].URI != "a+about://server" {
+		t.Errorf("reading a+about://server gave %+v", read.Contents)
+	}
+}
+
+func TestGatewayRewritesResourceLinks(t *testing.T) {
+	ctx := context.Background()
+	cs := connectGateway(t, "a")
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "a__list_items"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var links int
+	for _, c := range res.Content {
+		link, ok := c.(*mcp.ResourceLink)
+		if !ok {
+			continue
+		}
+		links++
+		// Each link must be readable through the gateway.
+		if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: link.URI}); err != nil {
+			t.Errorf("reading linked %s: %v", link.URI, err)
+		}
+	}
+	if links == 0 {
+		t.Fatalf("a__list_items returned no resource links: %+v", res.Content)
+	}
+
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{Name: "a__get_item", Arguments: map[string]any{"id": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var embedded *mcp.EmbeddedResource
+	for _, c := range res.Content {
+		if e, ok := c.(*mcp.EmbeddedResource); ok {
+			embedded = e
+		}
+	}
+	if embedded == nil || embedded.Resource.URI != "a+item://1" {
+		t.Errorf("a__get_item content = %+v, want a resource embedded as a+item://1", res.Content)
+	}
+}
+
+func TestGatewayPropagatesToolListChanges(t *testing.T) {
+	ctx := context.Background()
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "1.0.0"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "first"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	changed := make(chan struct{}, 10)
+	cs := connectUpstreams(t, map[string]*mcp.Server{"up": upstream}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) { changed <- struct{}{} },
+	})
+	names := func() []string {
+		var names []string
+		for tool, err := range cs.Tools(ctx, nil) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+	if tools := names(); !slices.Contains(tools, "up__first") || slices.Contains(tools, "up__second") {
+		t.Fatalf("tools = %v, want up__first only", tools)
+	}
+
+	mcp.AddTool(upstream, &mcp.Tool{Name: "second"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	// The gateway may also have announced its mirrored tools when the
+	// session started, so wait until the new tool shows up.
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case <-changed:
+		case <-deadline:
+			t.Fatalf("no tools/list_changed listing up__second; tools = %v", names())
+		}
+		if tools := names(); slices.Contains(tools, "up__second") {
+			if !slices.Contains(tools, "up__first") || !slices.Contains(tools, "hello") {
+				t.Errorf("tools = %v, want up__first and hello still there", tools)
+			}
+			return
+		}
+	}
+}
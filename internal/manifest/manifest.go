@@ -0,0 +1,218 @@
+// Package manifest describes everything an MCP server exposes.
+//
+// A Manifest is gathered the same way a real client would see it: by
+// connecting to the server over an in-memory transport and listing its
+// tools, resources, resource templates and prompts. This keeps generated
+// documentation and registry metadata (server.json) honest — they describe
+// what the server actually registers, not what someone remembered to write.
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Manifest is the full capability listing of a server.
+type Manifest struct {
+	Server            *mcp.Implementation     `json:"server"`
+	Instructions      string                  `json:"instructions,omitempty"`
+	Capabilities      *mcp.ServerCapabilities `json:"capabilities,omitempty"`
+	Tools             []*mcp.Tool             `json:"tools"`
+	Resources         []*mcp.Resource         `json:"resources"`
+	ResourceTemplates []*mcp.ResourceTemplate `json:"resourceTemplates"`
+	Prompts           []*mcp.Prompt           `json:"prompts"`
+}
+
+// Build connects to srv over an in-memory transport and lists everything it
+// exposes.
+func Build(ctx context.Context, srv *mcp.Server) (*Manifest, error) {
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := srv.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp-go-starter-describe", Version: "1.0.0"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+
+	init := cs.InitializeResult()
+	m := &Manifest{
+		Server:            init.ServerInfo,
+		Instructions:      init.Instructions,
+		Capabilities:      init.Capabilities,
+		Tools:             []*mcp.Tool{},
+		Resources:         []*mcp.Resource{},
+		ResourceTemplates: []*mcp.ResourceTemplate{},
+		Prompts:           []*mcp.Prompt{},
+	}
+	caps := init.Capabilities
+	if caps == nil {
+		return m, nil
+	}
+	if caps.Tools != nil {
+		for t, err := range cs.Tools(ctx, nil) {
+			if err != nil {
+				return nil, fmt.Errorf("listing tools: %w", err)
+			}
+			m.Tools = append(m.Tools, t)
+		}
+	}
+	if caps.Resources != nil {
+		for r, err := range cs.Resources(ctx, nil) {
+			if err != nil {
+				return nil, fmt.Errorf("listing resources: %w", err)
+			}
+			m.Resources = append(m.Resources, r)
+		}
+		for rt, err := range cs.ResourceTemplates(ctx, nil) {
+			if err != nil {
+				return nil, fmt.Errorf("listing resource templates: %w", err)
+			}
+			m.ResourceTemplates = append(m.ResourceTemplates, rt)
+		}
+	}
+	if caps.Prompts != nil {
+		for p, err := range cs.Prompts(ctx, nil) {
+			if err != nil {
+				return nil, fmt.Errorf("listing prompts: %w", err)
+			}
+			m.Prompts = append(m.Prompts, p)
+		}
+	}
+	return m, nil
+}
+
+// Summary is the compact listing of names recorded in server.json.
+type Summary struct {
+	Tools             []string `json:"tools"`
+	Resources         []string `json:"resources"`
+	ResourceTemplates []string `json:"resourceTemplates"`
+	Prompts           []string `json:"prompts"`
+}
+
+// Summary returns the sorted names of every tool, resource URI, resource
+// template and prompt.
+func (m *Manifest) Summary() Summary {
+	s := Summary{Tools: []string{}, Resources: []string{}, ResourceTemplates: []string{}, Prompts: []string{}}
+	for _, t := range m.Tools {
+		s.Tools = append(s.Tools, t.Name)
+	}
+	for _, r := range m.Resources {
+		s.Resources = append(s.Resources, r.URI)
+	}
+	for _, rt := range m.ResourceTemplates {
+		s.ResourceTemplates = append(s.ResourceTemplates, rt.URITemplate)
+	}
+	for _, p := range m.Prompts {
+		s.Prompts = append(s.Prompts, p.Name)
+	}
+	sort.Strings(s.Tools)
+	sort.Strings(s.Resources)
+	sort.Strings(s.ResourceTemplates)
+	sort.Strings(s.Prompts)
+	return s
+}
+
+// JSON renders the manifest as indented JSON.
+func (m *Manifest) JSON() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// Markdown renders the manifest as a human-readable reference document.
+// Icons are omitted; schemas are included as JSON code blocks.
+func (m *Manifest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s %s\n\n", m.Server.Name, m.Server.Version)
+
+	fmt.Fprintf(&b, "## Tools\n\n")
+	for _, t := range m.Tools {
+		fmt.Fprintf(&b, "### `%s`\n\n", t.Name)
+		if t.Title != "" {
+			fmt.Fprintf(&b, "**%s**\n\n", t.Title)
+		}
+		if t.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", t.Description)
+		}
+		if hints := annotationHints(t.Annotations); hints != "" {
+			fmt.Fprintf(&b, "Annotations: %s\n\n", hints)
+		}
+		writeSchema(&b, "Input schema", t.InputSchema)
+		writeSchema(&b, "Output schema", t.OutputSchema)
+	}
+
+	fmt.Fprintf(&b, "## Resources\n\n")
+	if len(m.Resources) > 0 {
+		fmt.Fprintf(&b, "| URI | Name | MIME type | Description |\n|-----|------|-----------|-------------|\n")
+		for _, r := range m.Resources {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", r.URI, r.Name, r.MIMEType, r.Description)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "## Resource Templates\n\n")
+	if len(m.ResourceTemplates) > 0 {
+		fmt.Fprintf(&b, "| URI template | Name | MIME type | Description |\n|--------------|------|-----------|-------------|\n")
+		for _, rt := range m.ResourceTemplates {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", rt.URITemplate, rt.Name, rt.MIMEType, rt.Description)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "## Prompts\n\n")
+	for _, p := range m.Prompts {
+		fmt.Fprintf(&b, "### `%s`\n\n", p.Name)
+		if p.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", p.Description)
+		}
+		for _, a := range p.Arguments {
+			required := ""
+			if a.Required {
+				required = " (required)"
+			}
+			fmt.Fprintf(&b, "- `%s`%s: %s\n", a.Name, required, a.Description)
+		}
+		if len(p.Arguments) > 0 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// annotationHints lists a tool's annotation hints as "name=value" pairs.
+func annotationHints(a *mcp.ToolAnnotations) string {
+	if a == nil {
+		return ""
+	}
+	hints := []string{
+		fmt.Sprintf("readOnlyHint=%t", a.ReadOnlyHint),
+		fmt.Sprintf("idempotentHint=%t", a.IdempotentHint),
+	}
+	if a.DestructiveHint != nil {
+		hints = append(hints, fmt.Sprintf("destructiveHint=%t", *a.DestructiveHint))
+	}
+	if a.OpenWorldHint != nil {
+		hints = append(hints, fmt.Sprintf("openWorldHint=%t", *a.OpenWorldHint))
+	}
+	return "`" + strings.Join(hints, "`, `") + "`"
+}
+
+func writeSchema(b *strings.Builder, label string, schema any) {
+	if schema == nil {
+		return
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n\n```json\n%s\n```\n\n", label, data)
+}
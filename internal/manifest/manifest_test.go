@@ -0,0 +1,145 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// testServer registers two tools, a resource, a resource template and a
+// prompt.
+func testServer() *mcp.Server {
+	s := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.2.3"}, &mcp.ServerOptions{Instructions: "Be nice"})
+	handler := func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	mcp.AddTool(s, &mcp.Tool{Name: "zeta", Description: "Last tool", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}, handler)
+	mcp.AddTool(s, &mcp.Tool{Name: "alpha", Description: "First tool"}, handler)
+	read := func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{}, nil
+	}
+	s.AddResource(&mcp.Resource{URI: "about://server", Name: "about", MIMEType: "text/plain"}, read)
+	s.AddResourceTemplate(&mcp.ResourceTemplate{URITemplate: "item://{id}", Name: "item"}, read)
+	s.AddPrompt(&mcp.Prompt{Name: "greet", Description: "Say hi", Arguments: []*mcp.PromptArgument{{Name: "name", Required: true}}},
+		func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+	return s
+}
+
+func TestBuild(t *testing.T) {
+	m, err := Build(context.Background(), testServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Server.Name != "test-server" || m.Server.Version != "1.2.3" || m.Instructions != "Be nice" {
+		t.Errorf("server = %+v %q", m.Server, m.Instructions)
+	}
+	want := Summary{
+		Tools:             []string{"alpha", "zeta"},
+		Resources:         []string{"about://server"},
+		ResourceTemplates: []string{"item://{id}"},
+		Prompts:           []string{"greet"},
+	}
+	if got := m.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summary() = %+v, want %+v", got, want)
+	}
+
+	md := m.Markdown()
+	for _, s := range []string{"# test-server 1.2.3", "### `alpha`", "Annotations: `readOnlyHint=true`", "| `about://server` | about | text/plain |", "- `name` (required)"} {
+		if !strings.Contains(md, s) {
+			t.Errorf("Markdown() lacks %q:\n%s", s, md)
+		}
+	}
+}
+
+func TestBuildEmptyServer(t *testing.T) {
+	m, err := Build(context.Background(), mcp.NewServer(&mcp.Implementation{Name: "empty", Version: "0"}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := m.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Empty lists, not nulls, so consumers can iterate without checks.
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"tools", "resources", "resourceTemplates", "prompts"} {
+		if list, ok := doc[key].([]any); !ok || len(list) != 0 {
+			t.Errorf("%s = %v, want []", key, doc[key])
+		}
+	}
+}
+
+func TestServerJSON(t *testing.T) {
+	m, err := Build(context.Background(), testServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := []byte(`{
+  "$schema": "https://example.com/schema.json",
+  "name": "old-name",
+  "version": "1.0.0",
+  "description": "kept",
+  "_meta": {
+    "other": true,
+    "` + PublisherMetaKey + `": {"tools": ["alpha", "gone"], "resources": [], "resourceTemplates": [], "prompts": ["greet"]}
+  }
+}`)
+
+	problems, err := CheckServerJSON(stale, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`name is "old-name", server reports "test-server"`,
+		`version is "1.0.0", server reports "1.2.3"`,
+		`tools: "zeta" is registered but not listed`,
+		`tools: "gone" is listed but not registered`,
+		`resources: "about://server" is registered but not listed`,
+		`resourceTemplates: "item://{id}" is registered but not listed`,
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("CheckServerJSON problems:\n%s\nwant:\n%s", strings.Join(problems, "\n"), strings.Join(want, "\n"))
+	}
+
+	updated, err := UpdateServerJSON(stale, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems, err := CheckServerJSON(updated, m); err != nil || len(problems) > 0 {
+		t.Errorf("after UpdateServerJSON: %v %v\n%s", problems, err, updated)
+	}
+	// Other fields survive, in their original order.
+	text := string(updated)
+	order := []string{`"$schema"`, `"name": "test-server"`, `"version": "1.2.3"`, `"description": "kept"`, `"other": true`}
+	last := -1
+	for _, s := range order {
+		i := strings.Index(text, s)
+		if i < last {
+			t.Errorf("%s missing or out of order in:\n%s", s, text)
+		}
+		last = i
+	}
+}
+
+func TestCheckServerJSONMissingSummary(t *testing.T) {
+	m, err := Build(context.Background(), testServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems, err := CheckServerJSON([]byte(`{"name": "test-server", "version": "1.2.3"}`), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "is missing") {
+		t.Errorf("problems = %v, want the summary reported missing", problems)
+	}
+}
@@ -0,0 +1,186 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// PublisherMetaKey is the server.json _meta key reserved for metadata the
+// publisher provides. The capability Summary is recorded under it.
+const PublisherMetaKey = "io.modelcontextprotocol.registry/publisher-provided"
+
+// CheckServerJSON reports every way the server.json document in data
+// disagrees with m: name, version, and the capability summary. An empty
+// result means the file is in sync.
+func CheckServerJSON(data []byte, m *Manifest) ([]string, error) {
+	var doc struct {
+		Name    string                     `json:"name"`
+		Version string                     `json:"version"`
+		Meta    map[string]json.RawMessage `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if doc.Name != m.Server.Name {
+		problems = append(problems, fmt.Sprintf("name is %q, server reports %q", doc.Name, m.Server.Name))
+	}
+	if doc.Version != m.Server.Version {
+		problems = append(problems, fmt.Sprintf("version is %q, server reports %q", doc.Version, m.Server.Version))
+	}
+
+	raw, ok := doc.Meta[PublisherMetaKey]
+	if !ok {
+		return append(problems, fmt.Sprintf("_meta[%q] is missing", PublisherMetaKey)), nil
+	}
+	var recorded Summary
+	if err := json.Unmarshal(raw, &recorded); err != nil {
+		return nil, fmt.Errorf("_meta[%q]: %w", PublisherMetaKey, err)
+	}
+	actual := m.Summary()
+	problems = append(problems, compareNames("tools", recorded.Tools, actual.Tools)...)
+	problems = append(problems, compareNames("resources", recorded.Resources, actual.Resources)...)
+	problems = append(problems, compareNames("resourceTemplates", recorded.ResourceTemplates, actual.ResourceTemplates)...)
+	problems = append(problems, compareNames("prompts", recorded.Prompts, actual.Prompts)...)
+	return problems, nil
+}
+
+func compareNames(kind string, recorded, actual []string) []string {
+	var problems []string
+	for _, name := range actual {
+		if !slices.Contains(recorded, name) {
+			problems = append(problems, fmt.Sprintf("%s: %q is registered but not listed", kind, name))
+		}
+	}
+	for _, name := range recorded {
+		if !slices.Contains(actual, name) {
+			problems = append(problems, fmt.Sprintf("%s: %q is listed but not registered", kind, name))
+		}
+	}
+	return problems
+}
+
+// UpdateServerJSON returns data with its name, version and capability
+// summary brought in line with m. All other fields, and the order of
+// existing keys, are preserved.
+func UpdateServerJSON(data []byte, m *Manifest) ([]byte, error) {
+	doc, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = doc.set("name", m.Server.Name); err != nil {
+		return nil, err
+	}
+	if doc, err = doc.set("version", m.Server.Version); err != nil {
+		return nil, err
+	}
+
+	var meta object
+	if raw, ok := doc.get("_meta"); ok {
+		if meta, err = decodeObject(raw); err != nil {
+			return nil, fmt.Errorf("_meta: %w", err)
+		}
+	}
+	if meta, err = meta.set(PublisherMetaKey, m.Summary()); err != nil {
+		return nil, err
+	}
+	metaData, err := meta.encode()
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = doc.set("_meta", json.RawMessage(metaData)); err != nil {
+		return nil, err
+	}
+
+	out, err := doc.encode()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, out, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// object is a JSON object that remembers the order of its keys, so that
+// rewriting a hand-maintained file doesn't reshuffle it.
+type object []field
+
+type field struct {
+	key   string
+	value json.RawMessage
+}
+
+func decodeObject(data []byte) (object, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	var obj object
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		obj = append(obj, field{key: key, value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (o object) get(key string) (json.RawMessage, bool) {
+	for _, f := range o {
+		if f.key == key {
+			return f.value, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces key's value in place, or appends it if absent.
+func (o object) set(key string, v any) (object, error) {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	for i, f := range o {
+		if f.key == key {
+			o[i].value = value
+			return o, nil
+		}
+	}
+	return append(o, field{key: key, value: value}), nil
+}
+
+func (o object) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(f.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+// Package recording captures and replays the JSON-RPC traffic of an MCP session.
+//
+// WHY RECORD?
+// Client bugs are hard to reproduce when you cannot see the exact messages
+// that crossed the transport. A recording is a JSONL file with one entry per
+// message — inbound (client → server) or outbound (server → client) — and a
+// timestamp. Recordings can be inspected by hand, shared in bug reports, or
+// fed back into a fresh server with Replay to check for regressions.
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Direction of a recorded message, from the server's point of view.
+const (
+	Inbound  = "in"  // Client → server
+	Outbound = "out" // Server → client
+)
+
+// Entry is a single line of a recording.
+type Entry struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// Transport is an [mcp.Transport] that delegates to another transport and
+// writes every message it reads or writes to Writer as a JSONL [Entry].
+type Transport struct {
+	Transport mcp.Transport
+	Writer    io.Writer
+}
+
+// Connect connects the underlying transport and returns a recording connection.
+func (t *Transport) Connect(ctx context.Context) (mcp.Connection, error) {
+	delegate, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingConn{delegate: delegate, enc: json.NewEncoder(t.Writer)}, nil
+}
+
+type recordingConn struct {
+	delegate mcp.Connection
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (c *recordingConn) SessionID() string { return c.delegate.SessionID() }
+
+func (c *recordingConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.delegate.Read(ctx)
+	if err == nil {
+		c.record(Inbound, msg)
+	}
+	return msg, err
+}
+
+// Write records msg before writing it: recorded afterwards, a request could
+// appear after the client's response to it.
+func (c *recordingConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	c.record(Outbound, msg)
+	return c.delegate.Write(ctx, msg)
+}
+
+func (c *recordingConn) Close() error {
+	return c.delegate.Close()
+}
+
+// record appends msg to the recording. Failures are deliberately swallowed:
+// a broken recording must never take down the session being recorded.
+func (c *recordingConn) record(direction string, msg jsonrpc.Message) {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.enc.Encode(Entry{Time: time.Now().UTC(), Direction: direction, Message: data})
+}
+
+// ReadEntries parses a JSONL recording.
+func ReadEntries(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	dec := json.NewDecoder(r)
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", len(entries)+1, err)
+		}
+		if e.Direction != Inbound && e.Direction != Outbound {
+			return nil, fmt.Errorf("entry %d: unknown direction %q", len(entries)+1, e.Direction)
+		}
+		entries = append(entries, e)
+	}
+}
@@ -0,0 +1,260 @@
+package recording
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ReplayOptions configures Replay. A nil *ReplayOptions uses the defaults.
+type ReplayOptions struct {
+	// Timeout is how long to wait for each expected outbound message, on
+	// top of the time the server took to send it in the recording (measured
+	// from the inbound message before it). Defaults to 5 seconds.
+	Timeout time.Duration
+	// Settle is how long to keep listening after the last entry for
+	// messages the recording doesn't have. Defaults to 500 milliseconds.
+	Settle time.Duration
+	// Ignore lists object keys (at any depth) that are dropped before
+	// comparing messages, for fields that legitimately differ between runs.
+	Ignore []string
+}
+
+// Diff describes one mismatch between a recording and a replay.
+// Want is nil for unexpected messages; Got is nil for missing ones.
+type Diff struct {
+	Index int // 1-based entry number in the recording, 0 for unexpected messages
+	Key   string
+	Want  json.RawMessage
+	Got   json.RawMessage
+}
+
+func (d Diff) String() string {
+	switch {
+	case d.Got == nil:
+		return fmt.Sprintf("entry %d (%s): missing\n  want: %s", d.Index, d.Key, d.Want)
+	case d.Want == nil:
+		return fmt.Sprintf("unexpected %s\n  got:  %s", d.Key, d.Got)
+	default:
+		return fmt.Sprintf("entry %d (%s): mismatch\n  want: %s\n  got:  %s", d.Index, d.Key, d.Want, d.Got)
+	}
+}
+
+// Replay connects srv to an in-memory client, sends it every inbound message
+// from entries, and compares what the server writes back with the recorded
+// outbound messages. Outbound messages are matched by key (response ID,
+// request method and ID, or notification method) rather than strict order,
+// since concurrent handlers may interleave.
+//
+// An empty result means the server reproduced the recording. This makes
+// recordings usable as regression tests:
+//
+//	diffs, err := recording.Replay(ctx, server.NewServer(), entries, nil)
+func Replay(ctx context.Context, srv *mcp.Server, entries []Entry, opts *ReplayOptions) ([]Diff, error) {
+	timeout, settle := 5*time.Second, 500*time.Millisecond
+	var ignore []string
+	if opts != nil {
+		if opts.Timeout > 0 {
+			timeout = opts.Timeout
+		}
+		if opts.Settle > 0 {
+			settle = opts.Settle
+		}
+		ignore = opts.Ignore
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := srv.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.Close()
+
+	conn, err := clientTransport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	received := make(chan jsonrpc.Message)
+	go func() {
+		for {
+			msg, err := conn.Read(ctx)
+			if err != nil {
+				close(received)
+				return
+			}
+			select {
+			case received <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	box := &inbox{received: received}
+	var diffs []Diff
+
+	var sent time.Time // When the last inbound entry was recorded
+	for i, e := range entries {
+		msg, err := jsonrpc.DecodeMessage(e.Message)
+		if err != nil {
+			return diffs, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		if e.Direction == Inbound {
+			if err := conn.Write(ctx, msg); err != nil {
+				return diffs, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			sent = e.Time
+			continue
+		}
+
+		// Slow handlers, such as long_task, get as long as they took when
+		// recorded.
+		wait := timeout
+		if !sent.IsZero() && e.Time.After(sent) {
+			wait += e.Time.Sub(sent)
+		}
+		key := messageKey(msg)
+		got, err := box.await(ctx, key, wait)
+		if err != nil {
+			return diffs, err
+		}
+		if got == nil {
+			diffs = append(diffs, Diff{Index: i + 1, Key: key, Want: e.Message})
+			continue
+		}
+		gotData, err := jsonrpc.EncodeMessage(got)
+		if err != nil {
+			return diffs, err
+		}
+		if !equalJSON(e.Message, gotData, ignore) {
+			diffs = append(diffs, Diff{Index: i + 1, Key: key, Want: e.Message, Got: gotData})
+		}
+	}
+
+	// Anything else the server sends is unexpected, whether it arrived while
+	// waiting for other messages or after the last one.
+	if err := box.drain(ctx, settle); err != nil {
+		return diffs, err
+	}
+	for _, m := range box.pending {
+		data, err := jsonrpc.EncodeMessage(m)
+		if err != nil {
+			return diffs, err
+		}
+		diffs = append(diffs, Diff{Key: messageKey(m), Got: data})
+	}
+	return diffs, nil
+}
+
+// inbox buffers messages from the server until a recorded entry claims them.
+type inbox struct {
+	received <-chan jsonrpc.Message
+	pending  []jsonrpc.Message
+	closed   bool
+}
+
+// await returns the first message with the given key, or nil if none
+// arrives within timeout or the connection closes first.
+func (in *inbox) await(ctx context.Context, key string, timeout time.Duration) (jsonrpc.Message, error) {
+	for i, m := range in.pending {
+		if messageKey(m) == key {
+			in.pending = append(in.pending[:i:i], in.pending[i+1:]...)
+			return m, nil
+		}
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for !in.closed {
+		select {
+		case m, ok := <-in.received:
+			if !ok {
+				in.closed = true
+			} else if messageKey(m) == key {
+				return m, nil
+			} else {
+				in.pending = append(in.pending, m)
+			}
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, nil
+}
+
+// drain adds every message that arrives until none has for quiet, or the
+// connection closes, to the pending messages.
+func (in *inbox) drain(ctx context.Context, quiet time.Duration) error {
+	timer := time.NewTimer(quiet)
+	defer timer.Stop()
+	for !in.closed {
+		select {
+		case m, ok := <-in.received:
+			if !ok {
+				in.closed = true
+				break
+			}
+			in.pending = append(in.pending, m)
+			timer.Reset(quiet)
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// messageKey identifies a message for matching purposes.
+func messageKey(msg jsonrpc.Message) string {
+	switch m := msg.(type) {
+	case *jsonrpc.Request:
+		if m.IsCall() {
+			return fmt.Sprintf("request %s #%v", m.Method, m.ID.Raw())
+		}
+		return "notification " + m.Method
+	case *jsonrpc.Response:
+		return fmt.Sprintf("response #%v", m.ID.Raw())
+	default:
+		return fmt.Sprintf("%T", msg)
+	}
+}
+
+// equalJSON reports whether a and b decode to the same value once the
+// ignored keys are removed.
+func equalJSON(a, b json.RawMessage, ignore []string) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(strip(va, ignore), strip(vb, ignore))
+}
+
+func strip(v any, ignore []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range ignore {
+			delete(v, k)
+		}
+		for k, child := range v {
+			v[k] = strip(child, ignore)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = strip(child, ignore)
+		}
+	}
+	return v
+}
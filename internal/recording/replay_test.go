@@ -0,0 +1,144 @@
+package recording_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/recording"
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/server"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var update = flag.Bool("update", false, "re-record testdata/demo.jsonl")
+
+const demoRecording = "testdata/demo.jsonl"
+
+// The server's instructions change whenever a tool does; they aren't what
+// the demo recording is about.
+var demoIgnore = []string{"instructions"}
+
+// recordDemo runs the demo flow against a fresh server and returns the
+// recording: hello, a resource, a prompt and a long_task slow enough to take
+// longer than the replay timeout.
+func recordDemo(t *testing.T) []byte {
+	t.Helper()
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	var buf bytes.Buffer
+	ss, err := server.NewServer().Connect(ctx, &recording.Transport{Transport: serverTransport, Writer: &buf}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "demo-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "hello", Arguments: map[string]any{"name": "Ada"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "about://server"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.GetPrompt(ctx, &mcp.GetPromptParams{Name: "greet", Arguments: map[string]string{"name": "Ada"}}); err != nil {
+		t.Fatal(err)
+	}
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{}, // SetProgressToken can't add to a nil map
+		Name:      "long_task",
+		Arguments: map[string]any{"taskName": "demo", "steps": 3},
+	}
+	params.SetProgressToken("demo")
+	if _, err := cs.CallTool(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+	cs.Close()
+	ss.Wait()
+	return buf.Bytes()
+}
+
+func TestReplayDemo(t *testing.T) {
+	if *update {
+		if err := os.WriteFile(demoRecording, recordDemo(t), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(demoRecording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := recording.ReadEntries(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// long_task took about 3s when recorded, so a 1s timeout only passes if
+	// the recorded delay is allowed for.
+	diffs, err := recording.Replay(context.Background(), server.NewServer(), entries, &recording.ReplayOptions{
+		Timeout: time.Second,
+		Ignore:  demoIgnore,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diffs {
+		t.Error(d)
+	}
+}
+
+func TestReplayReportsUnexpectedMessages(t *testing.T) {
+	f, err := os.Open(demoRecording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := recording.ReadEntries(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drop the hello response from the recording: the server's reply is
+	// then unexpected, as is its last message once the entries after it are
+	// gone too.
+	var trimmed []recording.Entry
+	var dropped int
+	for _, e := range entries {
+		if e.Direction == recording.Outbound && strings.Contains(string(e.Message), "Hello, Ada!") {
+			dropped++
+			continue
+		}
+		trimmed = append(trimmed, e)
+	}
+	if dropped != 1 {
+		t.Fatalf("found %d hello responses in %s, want 1", dropped, demoRecording)
+	}
+	last := len(trimmed) - 1
+	if trimmed[last].Direction != recording.Outbound {
+		t.Fatalf("%s doesn't end with a server message", demoRecording)
+	}
+	trimmed = trimmed[:last]
+
+	diffs, err := recording.Replay(context.Background(), server.NewServer(), trimmed, &recording.ReplayOptions{
+		Timeout: time.Second,
+		Ignore:  demoIgnore,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unexpected []string
+	for _, d := range diffs {
+		if d.Want != nil {
+			t.Errorf("unexpected diff: %v", d)
+			continue
+		}
+		unexpected = append(unexpected, string(d.Got))
+	}
+	if len(unexpected) != 2 || !strings.Contains(unexpected[0], "Hello, Ada!") {
+		t.Errorf("unexpected messages = %q, want the hello response and the final long_task response", unexpected)
+	}
+}
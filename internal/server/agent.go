@@ -0,0 +1,367 @@
+// agent.go — An agentic loop built on sampling with tools.
+//
+// run_agent hands a task to the client's LLM together with this server's own
+// tools (sampling/createMessage with "tools"). When the model asks for tool
+// calls, the server runs them against its registered handlers, sends back the
+// results and samples again, until the model gives a final answer or the step
+// limit is reached. Each step is one createMessage request.
+//
+// The client must declare the sampling "tools" capability. Only read-only
+// tools of this server's toolsets are offered, and none that ask the user or
+// the client's LLM for input (the elicitation and sampling toolsets, run_agent
+// included). Tools mirrored from upstream servers in gateway mode are never
+// offered: whatever their annotations say, this server can't vouch for them.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultAgentSteps     = 5
+	maxAgentSteps         = 10
+	defaultAgentMaxTokens = 1000
+)
+
+const defaultAgentSystemPrompt = "You can call tools to help complete the user's task. " +
+	"Call them as needed, then reply with a concise final answer."
+
+type agentInput struct {
+	Task         string   `json:"task" jsonschema:"What the agent should do"`
+	Tools        []string `json:"tools,omitempty" jsonschema:"Names of the tools to offer; defaults to all eligible tools"`
+	MaxSteps     int      `json:"maxSteps,omitempty" jsonschema:"Maximum sampling requests"`
+	MaxTokens    int      `json:"maxTokens,omitempty" jsonschema:"Maximum tokens per response"`
+	SystemPrompt string   `json:"systemPrompt,omitempty" jsonschema:"System prompt for the LLM"`
+}
+
+// agentToolCall records one tool call made on the model's behalf.
+type agentToolCall struct {
+	Step      int            `json:"step"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	IsError   bool           `json:"isError"`
+	Result    string         `json:"result"` // Text summary of the tool's content
+}
+
+// agentResult is run_agent's structured output.
+type agentResult struct {
+	Answer     string          `json:"answer"`
+	StopReason string          `json:"stopReason"` // "finalAnswer" or "maxSteps"
+	Steps      int             `json:"steps"`
+	ToolCalls  []agentToolCall `json:"toolCalls"`
+	Model      string          `json:"model,omitempty"`
+}
+
+// agentExcludedToolsets are the toolsets whose tools run_agent never offers:
+// they would interrupt the user, or recurse into the client's LLM.
+var agentExcludedToolsets = []string{"elicitation", "sampling"}
+
+// offeredToAgent reports whether run_agent may offer t to the model: only
+// read-only tools of the toolsets outside agentExcludedToolsets are. That
+// leaves out the catalog tools and tools mirrored in gateway mode, which
+// belong to no toolset.
+func offeredToAgent(t *mcp.Tool) bool {
+	toolset := toolsetOf(t.Name)
+	if toolset == "" || slices.Contains(agentExcludedToolsets, toolset) {
+		return false
+	}
+	return t.Annotations != nil && t.Annotations.ReadOnlyHint
+}
+
+// agentTools chooses the tools to offer from those the server lists. Only
+// what the model needs is kept: icons and output schemas are dropped.
+func agentTools(listed []*mcp.Tool, names []string) ([]*mcp.Tool, error) {
+	var tools []*mcp.Tool
+	for _, t := range listed {
+		if !offeredToAgent(t) || (len(names) > 0 && !slices.Contains(names, t.Name)) {
+			continue
+		}
+		tools = append(tools, &mcp.Tool{
+			Name:        t.Name,
+			Title:       t.Title,
+			Description: t.Description,
+			InputSchema: t.InputSchema,
+		})
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(tools, func(t *mcp.Tool) bool { return t.Name == name }) {
+			return nil, fmt.Errorf("tool %q is not available to the agent (it must be enabled and read-only)", name)
+		}
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("no tools are available to the agent")
+	}
+	return tools, nil
+}
+
+// summarizeContent renders tool content as one line of text.
+func summarizeContent(content []mcp.Content) string {
+	var parts []string
+	for _, c := range content {
+		parts = append(parts, describeContent(c))
+	}
+	return truncate(strings.Join(strings.Fields(strings.Join(parts, " ")), " "), 200)
+}
+
+func clientSupportsSamplingTools(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	return params != nil && params.Capabilities != nil &&
+		params.Capabilities.Sampling != nil && params.Capabilities.Sampling.Tools != nil
+}
+
+func (st *samplingTools) registerAgentTool(reg *toolRegistry) {
+	addTool(reg, &mcp.Tool{
+		Name:        "run_agent",
+		Description: "Have the connected LLM complete a task, calling this server's tools as it goes",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "RunAgentInput",
+			"properties": map[string]interface{}{
+				"task": map[string]interface{}{
+					"type":        "string",
+					"title":       "Task",
+					"description": "What the agent should do, e.g. \"Compare the weather in Tokyo and Paris\"",
+				},
+				"tools": map[string]interface{}{
+					"type":        "array",
+					"title":       "Tools",
+					"description": "Names of the tools to offer. Defaults to every enabled read-only tool",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"maxSteps": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Max Steps",
+					"description": "Maximum sampling requests; the last one asks for a final answer without tools",
+					"default":     defaultAgentSteps,
+					"minimum":     1,
+					"maximum":     maxAgentSteps,
+				},
+				"maxTokens": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Max Tokens",
+					"description": "Maximum tokens in each response",
+					"default":     defaultAgentMaxTokens,
+					"minimum":     1,
+				},
+				"systemPrompt": map[string]interface{}{
+					"type":        "string",
+					"title":       "System Prompt",
+					"description": "System prompt for the LLM; replaces the default",
+				},
+			},
+			"required": []string{"task"},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "AgentResult",
+			"properties": map[string]interface{}{
+				"answer": map[string]interface{}{
+					"type":        "string",
+					"description": "The model's final answer",
+				},
+				"stopReason": map[string]interface{}{
+					"type":        "string",
+					"description": "finalAnswer, or maxSteps if the model still wanted tools at the step limit",
+					"enum":        []string{"finalAnswer", "maxSteps"},
+				},
+				"steps": map[string]interface{}{
+					"type":        "integer",
+					"description": "Sampling requests made",
+				},
+				"toolCalls": map[string]interface{}{
+					"type":        "array",
+					"description": "Tool calls made for the model, in order",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"step":      map[string]interface{}{"type": "integer"},
+							"tool":      map[string]interface{}{"type": "string"},
+							"arguments": map[string]interface{}{"type": "object"},
+							"isError":   map[string]interface{}{"type": "boolean"},
+							"result":    map[string]interface{}{"type": "string"},
+						},
+						"required": []string{"step", "tool", "arguments", "isError", "result"},
+					},
+				},
+				"model": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the model that gave the last response",
+				},
+			},
+			"required": []string{"answer", "stopReason", "steps", "toolCalls"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false), // Only read-only tools are offered
+			OpenWorldHint:   boolPtr(true),  // Offered tools may reach external services
+		},
+		Icons: []mcp.Icon{
+			{
+				Source:   ROBOT_ICON,
+				MIMEType: "image/png",
+				Sizes:    []string{"256x256"},
+			},
+		},
+	}, st.runAgentHandler)
+}
+
+// runAgentHandler runs the sampling loop, reporting each step as progress.
+func (st *samplingTools) runAgentHandler(ctx context.Context, req *mcp.CallToolRequest, input agentInput) (*mcp.CallToolResult, any, error) {
+	if !clientSupportsSamplingTools(req.Session) {
+		return nil, nil, unsupportedCapability("sampling.tools", "This client does not support tool use in sampling requests")
+	}
+
+	listed, err := st.self.listTools(ctx, req.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	tools, err := agentTools(listed, input.Tools)
+	if err != nil {
+		return nil, nil, invalidArgument("%w", err)
+	}
+
+	maxSteps := input.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = defaultAgentSteps
+	}
+	maxTokens := input.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultAgentMaxTokens
+	}
+	systemPrompt := input.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = defaultAgentSystemPrompt
+	}
+
+	progressToken := req.Params.GetProgressToken()
+	notify := func(step int, message string) {
+		if progressToken != nil {
+			_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: progressToken,
+				Progress:      float64(step) / float64(maxSteps),
+				Total:         1.0,
+				Message:       message,
+			})
+		}
+	}
+
+	messages := []*mcp.SamplingMessageV2{
+		{Role: "user", Content: []mcp.Content{&mcp.TextContent{Text: input.Task}}},
+	}
+	out := agentResult{ToolCalls: []agentToolCall{}}
+	for step := 1; step <= maxSteps; step++ {
+		out.Steps = step
+		params := &mcp.CreateMessageWithToolsParams{
+			Messages:     messages,
+			MaxTokens:    int64(maxTokens),
+			SystemPrompt: systemPrompt,
+			Tools:        tools,
+			ToolChoice:   &mcp.ToolChoice{Mode: "auto"},
+		}
+		if step == maxSteps {
+			params.ToolChoice.Mode = "none" // Last step: ask for an answer
+		}
+		notify(step-1, fmt.Sprintf("Step %d/%d: waiting for the model", step, maxSteps))
+		result, err := req.Session.CreateMessageWithTools(ctx, params)
+		if err != nil {
+			return nil, nil, internalError("Sampling failed at step %d: %w", step, err).mayRetry()
+		}
+		out.Model = result.Model
+		messages = append(messages, &mcp.SamplingMessageV2{Role: "assistant", Content: result.Content})
+
+		var text []string
+		var uses []*mcp.ToolUseContent
+		for _, c := range result.Content {
+			switch c := c.(type) {
+			case *mcp.ToolUseContent:
+				uses = append(uses, c)
+			case *mcp.TextContent:
+				text = append(text, c.Text)
+			}
+		}
+		out.Answer = strings.Join(text, "\n")
+		if len(uses) == 0 {
+			out.StopReason = "finalAnswer"
+			break
+		}
+		if step == maxSteps {
+			out.StopReason = "maxSteps"
+			break
+		}
+
+		// Every tool_use needs a matching tool_result in the next user message.
+		var results []mcp.Content
+		for _, use := range uses {
+			notify(step-1, fmt.Sprintf("Step %d/%d: calling %s", step, maxSteps, use.Name))
+			if use.Input == nil {
+				use.Input = map[string]any{}
+			}
+			res := st.callAgentTool(ctx, req.Session, tools, use)
+			results = append(results, res)
+			out.ToolCalls = append(out.ToolCalls, agentToolCall{
+				Step:      step,
+				Tool:      use.Name,
+				Arguments: use.Input,
+				IsError:   res.IsError,
+				Result:    summarizeContent(res.Content),
+			})
+		}
+		messages = append(messages, &mcp.SamplingMessageV2{Role: "user", Content: results})
+	}
+	notify(maxSteps, "Complete!")
+
+	var text strings.Builder
+	if out.StopReason == "maxSteps" {
+		fmt.Fprintf(&text, "Stopped after %d steps without a final answer.\n", out.Steps)
+	}
+	if out.Answer != "" {
+		fmt.Fprintf(&text, "%s\n", out.Answer)
+	}
+	if len(out.ToolCalls) > 0 {
+		text.WriteString("\nTool calls:\n")
+		for _, call := range out.ToolCalls {
+			args, _ := json.Marshal(call.Arguments)
+			status := "→"
+			if call.IsError {
+				status = "→ error:"
+			}
+			fmt.Fprintf(&text, "  %d. %s %s %s %s\n", call.Step, call.Tool, args, status, call.Result)
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, out, nil
+}
+
+// callAgentTool runs one tool call the model asked for. Failures, including
+// a tool that wasn't offered, are reported to the model as error results.
+func (st *samplingTools) callAgentTool(ctx context.Context, ss *mcp.ServerSession, tools []*mcp.Tool, use *mcp.ToolUseContent) *mcp.ToolResultContent {
+	errorResult := func(text string) *mcp.ToolResultContent {
+		return &mcp.ToolResultContent{
+			ToolUseID: use.ID,
+			Content:   []mcp.Content{&mcp.TextContent{Text: text}},
+			IsError:   true,
+		}
+	}
+	if !slices.ContainsFunc(tools, func(t *mcp.Tool) bool { return t.Name == use.Name }) {
+		return errorResult(fmt.Sprintf("Tool %q is not available", use.Name))
+	}
+	res, err := st.self.callTool(ctx, ss, use.Name, use.Input)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return &mcp.ToolResultContent{
+		ToolUseID:         use.ID,
+		Content:           res.Content,
+		StructuredContent: res.StructuredContent,
+		IsError:           res.IsError,
+	}
+}
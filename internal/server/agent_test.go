@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestOfferedToAgent(t *testing.T) {
+	SetDefaultToolsets([]string{"demo", "weather", "tasks", "sampling", "elicitation", "workspace"})
+	t.Cleanup(func() { SetDefaultToolsets(nil) })
+	cs := connect(t, NewServer(), nil)
+
+	offered := map[string]bool{}
+	for tool, err := range cs.Tools(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		offered[tool.Name] = offeredToAgent(tool)
+	}
+	for name, want := range map[string]bool{
+		"hello":           true,
+		"get_weather":     true,
+		"get_task":        true,
+		"read_file":       true,
+		"start_long_task": false, // Not read-only
+		"cancel_task":     false,
+		"enable_toolset":  false,
+		"confirm_action":  false, // Read-only, but asks the user
+		"plan_trip":       false,
+		"ask_llm":         false, // Calls the client's LLM
+		"run_agent":       false,
+		"list_toolsets":   false, // Catalog tools belong to no toolset
+	} {
+		got, ok := offered[name]
+		if !ok {
+			t.Errorf("tool %s isn't listed", name)
+		} else if got != want {
+			t.Errorf("offeredToAgent(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	// Tools mirrored in gateway mode are never offered, whatever their
+	// annotations or upstream names.
+	for _, name := range []string{"a__hello", "a__confirm_action", "a__ask_llm"} {
+		if offeredToAgent(&mcp.Tool{Name: name, Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}) {
+			t.Errorf("offeredToAgent(%s) = true for a mirrored tool", name)
+		}
+	}
+
+	tools, err := agentTools([]*mcp.Tool{{Name: "plan_trip", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}}, []string{"plan_trip"})
+	if err == nil {
+		t.Errorf("agentTools offered %v when asked for plan_trip", tools)
+	}
+}
+
+// connectAgent connects a client whose LLM answers with reply to a server
+// with the demo and sampling toolsets, recording each sampling request.
+func connectAgent(t *testing.T, reply func(*mcp.CreateMessageWithToolsParams) []mcp.Content) (*mcp.ClientSession, *[]*mcp.CreateMessageWithToolsParams) {
+	t.Helper()
+	SetDefaultToolsets([]string{"demo", "sampling"})
+	t.Cleanup(func() { SetDefaultToolsets(nil) })
+	var requests []*mcp.CreateMessageWithToolsParams
+	cs := connect(t, NewServer(), &mcp.ClientOptions{
+		CreateMessageWithToolsHandler: func(_ context.Context, req *mcp.CreateMessageWithToolsRequest) (*mcp.CreateMessageWithToolsResult, error) {
+			requests = append(requests, req.Params)
+			return &mcp.CreateMessageWithToolsResult{Role: "assistant", Model: "test", Content: reply(req.Params)}, nil
+		},
+	})
+	return cs, &requests
+}
+
+func callAgent(t *testing.T, cs *mcp.ClientSession, args map[string]any) agentResult {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "run_agent", Arguments: args})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("run_agent failed: %+v", res.Content)
+	}
+	raw, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out agentResult
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestRunAgentRoundTrip(t *testing.T) {
+	cs, requests := connectAgent(t, func(params *mcp.CreateMessageWithToolsParams) []mcp.Content {
+		last := params.Messages[len(params.Messages)-1]
+		if result, ok := last.Content[0].(*mcp.ToolResultContent); ok {
+			return []mcp.Content{&mcp.TextContent{Text: "The tool said: " + result.Content[0].(*mcp.TextContent).Text}}
+		}
+		return []mcp.Content{&mcp.ToolUseContent{ID: "use-1", Name: "hello", Input: map[string]any{"name": "Ada"}}}
+	})
+
+	out := callAgent(t, cs, map[string]any{"task": "Greet Ada"})
+	if out.StopReason != "finalAnswer" || out.Steps != 2 {
+		t.Errorf("stopped with %q after %d steps, want finalAnswer after 2", out.StopReason, out.Steps)
+	}
+	if want := "The tool said: Hello, Ada! Welcome to MCP."; out.Answer != want {
+		t.Errorf("answer = %q, want %q", out.Answer, want)
+	}
+	if len(out.ToolCalls) != 1 || out.ToolCalls[0].Tool != "hello" || out.ToolCalls[0].IsError {
+		t.Errorf("tool calls = %+v, want one successful hello", out.ToolCalls)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("%d sampling requests, want 2", len(*requests))
+	}
+	first, second := (*requests)[0], (*requests)[1]
+	var offered []string
+	for _, tool := range first.Tools {
+		offered = append(offered, tool.Name)
+	}
+	if !slices.Contains(offered, "hello") || slices.Contains(offered, "ask_llm") || slices.Contains(offered, "run_agent") {
+		t.Errorf("offered %v, want hello but not the sampling tools", offered)
+	}
+	// The second request replays the tool_use and answers it.
+	if len(second.Messages) != 3 || second.Messages[1].Role != "assistant" || second.Messages[2].Role != "user" {
+		t.Fatalf("second request messages = %+v, want task, tool_use, tool_result", second.Messages)
+	}
+	result, ok := second.Messages[2].Content[0].(*mcp.ToolResultContent)
+	if !ok || result.ToolUseID != "use-1" || result.IsError {
+		t.Errorf("tool result = %+v, want a successful result for use-1", second.Messages[2].Content[0])
+	}
+}
+
+func TestRunAgentStopsAtMaxSteps(t *testing.T) {
+	cs, requests := connectAgent(t, func(params *mcp.CreateMessageWithToolsParams) []mcp.Content {
+		return []mcp.Content{&mcp.ToolUseContent{ID: fmt.Sprint("use-", len(params.Messages)), Name: "hello", Input: map[string]any{"name": "Ada"}}}
+	})
+
+	out := callAgent(t, cs, map[string]any{"task": "Greet forever", "maxSteps": 3})
+	if out.StopReason != "maxSteps" || out.Steps != 3 {
+		t.Errorf("stopped with %q after %d steps, want maxSteps after 3", out.StopReason, out.Steps)
+	}
+	// The tools asked for at the last step aren't run.
+	if len(out.ToolCalls) != 2 {
+		t.Errorf("%d tool calls, want 2", len(out.ToolCalls))
+	}
+	for i, params := range *requests {
+		want := "auto"
+		if i == len(*requests)-1 {
+			want = "none"
+		}
+		if params.ToolChoice == nil || params.ToolChoice.Mode != want {
+			t.Errorf("request %d tool choice = %+v, want %s", i+1, params.ToolChoice, want)
+		}
+	}
+}
+
+func TestRunAgentRejectsMaxTokens(t *testing.T) {
+	cs, requests := connectAgent(t, func(*mcp.CreateMessageWithToolsParams) []mcp.Content {
+		return []mcp.Content{&mcp.TextContent{Text: "done"}}
+	})
+	for _, maxTokens := range []int{0, -5} {
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "run_agent", Arguments: map[string]any{"task": "t", "maxTokens": maxTokens}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code := toolError(t, res).Code; code != CodeInvalidArgument {
+			t.Errorf("maxTokens %d: code = %s, want %s", maxTokens, code, CodeInvalidArgument)
+		}
+	}
+	if len(*requests) != 0 {
+		t.Errorf("%d sampling requests made with invalid maxTokens", len(*requests))
+	}
+}
@@ -0,0 +1,233 @@
+// charts.go — Tools that return images.
+//
+// IMAGE CONTENT:
+// A tool result can carry images as well as text. ImageContent holds the raw
+// bytes and a MIME type; the SDK base64-encodes them on the wire. Clients
+// that display images show the chart, and the text alongside it carries the
+// exact numbers for models and clients that don't.
+//
+//   - render_chart:   Draws a line or bar chart of the series passed in
+//   - chart_forecast: Draws a city's forecast highs and lows
+//
+// Charts are drawn by internal/chart, using only the standard library.
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/chart"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type renderChartInput struct {
+	Kind   string      `json:"kind"`
+	Title  string      `json:"title,omitempty"`
+	Labels []string    `json:"labels,omitempty"`
+	Series []seriesArg `json:"series"`
+	Width  int         `json:"width,omitempty"`
+	Height int         `json:"height,omitempty"`
+}
+
+type seriesArg struct {
+	Name   string    `json:"name,omitempty"`
+	Values []float64 `json:"values"`
+}
+
+type chartForecastInput struct {
+	City string `json:"city"`
+	Days int    `json:"days,omitempty"`
+	Unit string `json:"unit,omitempty"`
+	Kind string `json:"kind,omitempty"`
+}
+
+var chartKindProperty = map[string]interface{}{
+	"type":        "string",
+	"title":       "Kind",
+	"description": "Line or bar chart",
+	"enum":        []string{string(chart.Line), string(chart.Bar)},
+}
+
+// registerChartTools registers the "charts" toolset.
+func registerChartTools(reg *toolRegistry) {
+	addTool(reg, &mcp.Tool{
+		Name:        "render_chart",
+		Description: "Draw a line or bar chart of one or more numeric series and return it as a PNG image",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "RenderChartInput",
+			"properties": map[string]interface{}{
+				"kind": chartKindProperty,
+				"title": map[string]interface{}{
+					"type":        "string",
+					"title":       "Title",
+					"description": "Title drawn above the chart",
+				},
+				"labels": map[string]interface{}{
+					"type":        "array",
+					"title":       "Labels",
+					"description": "x-axis label for each value; as many as each series has values",
+					"items":       map[string]interface{}{"type": "string"},
+					"maxItems":    chart.MaxPoints,
+				},
+				"series": map[string]interface{}{
+					"type":        "array",
+					"title":       "Series",
+					"description": "The data; every series needs the same number of values",
+					"minItems":    1,
+					"maxItems":    chart.MaxSeries,
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"name": map[string]interface{}{
+								"type":        "string",
+								"description": "Name shown in the legend",
+							},
+							"values": map[string]interface{}{
+								"type": "array",
+								"items": map[string]interface{}{
+									"type":    "number",
+									"minimum": -chart.MaxValue,
+									"maximum": chart.MaxValue,
+								},
+								"minItems": 1,
+								"maxItems": chart.MaxPoints,
+							},
+						},
+						"required": []string{"values"},
+					},
+				},
+				"width": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Width",
+					"description": "Image width in pixels",
+					"minimum":     chart.MinWidth,
+					"maximum":     chart.MaxWidth,
+					"default":     chart.DefaultWidth,
+				},
+				"height": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Height",
+					"description": "Image height in pixels",
+					"minimum":     chart.MinHeight,
+					"maximum":     chart.MaxHeight,
+					"default":     chart.DefaultHeight,
+				},
+			},
+			"required": []string{"kind", "series"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, renderChartHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:        "chart_forecast",
+		Description: "Draw a city's daily forecast highs and lows as a PNG chart",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ChartForecastInput",
+			"properties": map[string]interface{}{
+				"city": map[string]interface{}{
+					"type":        "string",
+					"title":       "City",
+					"description": "City name to chart the forecast for",
+				},
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Days",
+					"description": "Number of days to forecast, starting today",
+					"minimum":     1,
+					"maximum":     maxForecastDays,
+					"default":     defaultForecastDays,
+				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"title":       "Unit",
+					"description": "Temperature unit",
+					"enum":        []string{"celsius", "fahrenheit"},
+					"default":     "celsius",
+				},
+				"kind": chartKindProperty,
+			},
+			"required": []string{"city"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true), // May call a real weather API, depending on the provider
+		},
+		Icons: []mcp.Icon{
+			{
+				Source:   SUN_BEHIND_CLOUD_ICON,
+				MIMEType: "image/png",
+				Sizes:    []string{"256x256"},
+			},
+		},
+	}, chartForecastHandler)
+}
+
+func renderChartHandler(_ context.Context, _ *mcp.CallToolRequest, input renderChartInput) (*mcp.CallToolResult, any, error) {
+	c := chart.Chart{
+		Kind:   chart.Kind(input.Kind),
+		Title:  input.Title,
+		Labels: input.Labels,
+		Width:  input.Width,
+		Height: input.Height,
+	}
+	for _, s := range input.Series {
+		c.Series = append(c.Series, chart.Series{Name: s.Name, Values: s.Values})
+	}
+	res, err := chartResult(c, "")
+	return res, nil, err
+}
+
+func chartForecastHandler(ctx context.Context, _ *mcp.CallToolRequest, input chartForecastInput) (*mcp.CallToolResult, any, error) {
+	forecast, err := loadForecast(ctx, forecastInput{City: input.City, Days: input.Days, Unit: input.Unit})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kind := chart.Kind(input.Kind)
+	if kind == "" {
+		kind = chart.Line
+	}
+	c := chart.Chart{
+		Kind:  kind,
+		Title: fmt.Sprintf("%s (%s)", forecast.Location, unitSymbol(forecast.Unit)),
+		Series: []chart.Series{
+			{Name: "High"},
+			{Name: "Low"},
+		},
+	}
+	for _, day := range forecast.Days {
+		label := day.Date
+		if len(label) == len("2006-01-02") {
+			label = label[5:] // MM-DD
+		}
+		c.Labels = append(c.Labels, label)
+		c.Series[0].Values = append(c.Series[0].Values, day.High)
+		c.Series[1].Values = append(c.Series[1].Values, day.Low)
+	}
+	res, err := chartResult(c, forecastText(forecast))
+	return res, nil, err
+}
+
+// chartResult renders c as the image content of a tool result, with a short
+// description of it (or text, if given) alongside.
+func chartResult(c chart.Chart, text string) (*mcp.CallToolResult, error) {
+	data, err := c.PNG()
+	if err != nil {
+		return nil, invalidArgument("Can't draw the chart: %w", err)
+	}
+	if text == "" {
+		text = fmt.Sprintf("%s chart of %d series with %d values each", c.Kind, len(c.Series), len(c.Series[0].Values))
+		if c.Title != "" {
+			text += fmt.Sprintf(": %q", c.Title)
+		}
+	}
+	return toolResult(text, &mcp.ImageContent{Data: data, MIMEType: "image/png"}), nil
+}
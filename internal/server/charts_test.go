@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"testing"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/chart"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// chartSize decodes the PNG a chart tool returned and returns its size.
+func chartSize(t *testing.T, res *mcp.CallToolResult) (int, int) {
+	t.Helper()
+	if res.IsError {
+		t.Fatalf("chart failed: %+v", res.Content)
+	}
+	if len(res.Content) != 2 {
+		t.Fatalf("content = %+v, want text and an image", res.Content)
+	}
+	img, ok := res.Content[1].(*mcp.ImageContent)
+	if !ok || img.MIMEType != "image/png" {
+		t.Fatalf("content[1] = %+v, want a PNG", res.Content[1])
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Width, cfg.Height
+}
+
+func TestRenderChart(t *testing.T) {
+	ctx := context.Background()
+	cs := connect(t, NewServer(), nil)
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		width, height int
+	}{
+		{"default size", map[string]any{"kind": "line", "series": []any{map[string]any{"values": []any{1, 2, 3}}}}, chart.DefaultWidth, chart.DefaultHeight},
+		{"single point", map[string]any{"kind": "bar", "labels": []any{"a"}, "series": []any{map[string]any{"values": []any{4}}}, "width": 300, "height": 200}, 300, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "render_chart", Arguments: tt.args})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w, h := chartSize(t, res); w != tt.width || h != tt.height {
+				t.Errorf("image is %dx%d, want %dx%d", w, h, tt.width, tt.height)
+			}
+		})
+	}
+
+	for name, args := range map[string]map[string]any{
+		"mismatched labels": {"kind": "line", "labels": []any{"a"}, "series": []any{map[string]any{"values": []any{1, 2}}}},
+		"mismatched series": {"kind": "bar", "series": []any{map[string]any{"values": []any{1, 2}}, map[string]any{"values": []any{1}}}},
+		"empty series":      {"kind": "line", "series": []any{map[string]any{"values": []any{}}}},
+		"no series":         {"kind": "line", "series": []any{}},
+		"too large":         {"kind": "line", "series": []any{map[string]any{"values": []any{1e300}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "render_chart", Arguments: args})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code := toolError(t, res).Code; code != CodeInvalidArgument {
+				t.Errorf("code = %s, want %s", code, CodeInvalidArgument)
+			}
+		})
+	}
+}
+
+func TestChartForecast(t *testing.T) {
+	cs := connect(t, NewServer(), nil)
+	for _, kind := range []string{"line", "bar"} {
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "chart_forecast", Arguments: map[string]any{"city": "London", "days": 1, "kind": kind}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w, h := chartSize(t, res); w != chart.DefaultWidth || h != chart.DefaultHeight {
+			t.Errorf("%s chart is %dx%d", kind, w, h)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+// confirm.go — A server-side confirmation gate for destructive tools.
+//
+// WHY A GATE?
+// DestructiveHint tells a client that a tool may delete or overwrite data,
+// but acting on it is up to the client, and many just run the tool. The gate
+// enforces it here: before a destructive tool (or one named in the policy)
+// runs, the user is asked to confirm through elicitation. Clients that can't
+// elicit get the policy's fallback instead — deny by default.
+//
+// A tool is destructive unless it is read-only or says DestructiveHint:
+// false; per the spec, a tool without annotations counts as destructive.
+// Every decision is kept in the confirmations://log resource.
+//
+// The gate sits beneath the loopback, so tools run_agent calls on the
+// model's behalf are gated too. MCP_CONFIRM_TOOLS and MCP_CONFIRM_FALLBACK
+// set the policy (see ConfirmPolicyFromEnv).
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/elicit"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ConfirmFallback is what the gate does when the client can't elicit.
+type ConfirmFallback string
+
+const (
+	ConfirmDeny  ConfirmFallback = "deny"
+	ConfirmAllow ConfirmFallback = "allow"
+)
+
+// ConfirmPolicy chooses which tools need confirmation.
+type ConfirmPolicy struct {
+	Tools    []string        // Confirmed as well as destructive tools
+	Fallback ConfirmFallback // "" means ConfirmDeny
+}
+
+// The policy new servers start with.
+var confirmPolicy ConfirmPolicy
+
+// SetConfirmPolicy sets the confirmation policy for new servers.
+func SetConfirmPolicy(p ConfirmPolicy) {
+	confirmPolicy = p
+}
+
+// ConfirmPolicyFromEnv reads MCP_CONFIRM_TOOLS, a comma-separated list of
+// extra tools to confirm, and MCP_CONFIRM_FALLBACK, "deny" (the default) or
+// "allow".
+func ConfirmPolicyFromEnv() (ConfirmPolicy, error) {
+	var p ConfirmPolicy
+	for _, name := range strings.Split(os.Getenv("MCP_CONFIRM_TOOLS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			p.Tools = append(p.Tools, name)
+		}
+	}
+	switch v := ConfirmFallback(strings.TrimSpace(os.Getenv("MCP_CONFIRM_FALLBACK"))); v {
+	case "", ConfirmDeny, ConfirmAllow:
+		p.Fallback = v
+	default:
+		return ConfirmPolicy{}, fmt.Errorf("MCP_CONFIRM_FALLBACK: unknown fallback %q (want deny or allow)", v)
+	}
+	return p, nil
+}
+
+// maxConfirmDecisions is how many decisions the log keeps.
+const maxConfirmDecisions = 100
+
+// confirmDecision records one gated call.
+type confirmDecision struct {
+	Time    time.Time `json:"time"`
+	Tool    string    `json:"tool"`
+	Why     string    `json:"why"`     // "destructive" or "configured"
+	Outcome string    `json:"outcome"` // See the outcome constants
+	Allowed bool      `json:"allowed"`
+	Detail  string    `json:"detail,omitempty"`
+}
+
+// Decision outcomes.
+const (
+	outcomeConfirmed = "confirmed" // The user allowed the call
+	outcomeRejected  = "rejected"  // The user submitted the form without allowing it
+	outcomeDeclined  = "declined"
+	outcomeCancelled = "cancelled"
+	outcomeFailed    = "failed"   // Elicitation failed
+	outcomeFallback  = "fallback" // The client can't elicit
+)
+
+// gateForm is the form shown before a gated call.
+type gateForm struct {
+	Allow bool `json:"allow" elicit:"title=Allow;description=Run the tool"`
+}
+
+// confirmGate asks the user before gated tool calls run.
+type confirmGate struct {
+	policy ConfirmPolicy
+	tools  *toolCache
+
+	mu        sync.Mutex
+	decisions []confirmDecision
+}
+
+func newConfirmGate(policy ConfirmPolicy, tools *toolCache) *confirmGate {
+	return &confirmGate{policy: policy, tools: tools}
+}
+
+func (g *confirmGate) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	self := &loopback{handler: next}
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if method != "tools/call" || !ok || call.Session == nil {
+			return next(ctx, method, req)
+		}
+		// Unknown tools go straight through for the SDK to reject.
+		tool, err := g.tools.lookup(ctx, self, call.Session, call.Params.Name)
+		if err != nil {
+			return nil, err
+		}
+		if tool == nil {
+			return next(ctx, method, req)
+		}
+		why := g.why(tool)
+		if why == "" {
+			return next(ctx, method, req)
+		}
+
+		d := g.decide(ctx, call, tool, why)
+		g.record(d)
+		if !d.Allowed {
+			return d.toolError().result(), nil
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// why says why t needs confirmation, or "" if it doesn't.
+func (g *confirmGate) why(t *mcp.Tool) string {
+	if slices.Contains(g.policy.Tools, t.Name) {
+		return "configured"
+	}
+	a := t.Annotations
+	if a == nil || (!a.ReadOnlyHint && (a.DestructiveHint == nil || *a.DestructiveHint)) {
+		return "destructive"
+	}
+	return ""
+}
+
+// decide asks the user whether call may run, or applies the fallback.
+func (g *confirmGate) decide(ctx context.Context, call *mcp.CallToolRequest, t *mcp.Tool, why string) confirmDecision {
+	d := confirmDecision{Time: time.Now(), Tool: t.Name, Why: why}
+	if !clientSupportsElicitation(call.Session) {
+		d.Outcome = outcomeFallback
+		d.Allowed = g.policy.Fallback == ConfirmAllow
+		if !d.Allowed {
+			d.Detail = "it needs confirmation and the client can't ask the user"
+		}
+		return d
+	}
+
+	form, err := elicit.Ask[gateForm](ctx, call.Session, confirmMessage(t, why, call.Params.Arguments))
+	switch {
+	case errors.Is(err, elicit.ErrDeclined):
+		d.Outcome, d.Detail = outcomeDeclined, "the user declined"
+	case errors.Is(err, elicit.ErrCancelled):
+		d.Outcome, d.Detail = outcomeCancelled, "the user cancelled the confirmation"
+	case err != nil:
+		d.Outcome, d.Detail = outcomeFailed, fmt.Sprintf("confirmation failed: %v", err)
+	case !form.Allow:
+		d.Outcome, d.Detail = outcomeRejected, "the user did not allow it"
+	default:
+		d.Outcome, d.Allowed = outcomeConfirmed, true
+	}
+	return d
+}
+
+// toolError reports the refusal of a call that wasn't allowed.
+func (d confirmDecision) toolError() *ToolError {
+	switch d.Outcome {
+	case outcomeFallback:
+		return unsupportedCapability("elicitation.form", "%s was not run: %s", d.Tool, d.Detail)
+	case outcomeFailed:
+		return internalError("%s was not run: %s", d.Tool, d.Detail).mayRetry()
+	}
+	e := permissionDenied("%s was not run: %s", d.Tool, d.Detail).withDetail("outcome", d.Outcome)
+	if d.Outcome == outcomeCancelled {
+		e.mayRetry() // The user may have dismissed the form by mistake
+	}
+	return e
+}
+
+// confirmMessage is the text of the confirmation form.
+func confirmMessage(t *mcp.Tool, why string, args json.RawMessage) string {
+	name := t.Name
+	if t.Title != "" {
+		name = fmt.Sprintf("%s (%s)", t.Title, t.Name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Allow %s to run?", name)
+	if why == "destructive" {
+		b.WriteString(" It may delete or overwrite data.")
+	}
+	if t.Description != "" {
+		fmt.Fprintf(&b, "\n\n%s", t.Description)
+	}
+	if s := string(args); s != "" && s != "null" && s != "{}" {
+		fmt.Fprintf(&b, "\n\nArguments: %s", truncate(s, 500))
+	}
+	return b.String()
+}
+
+func (g *confirmGate) record(d confirmDecision) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.decisions = append(g.decisions, d)
+	if len(g.decisions) > maxConfirmDecisions {
+		g.decisions = slices.Delete(g.decisions, 0, len(g.decisions)-maxConfirmDecisions)
+	}
+}
+
+// registerResources registers confirmations://log.
+func (g *confirmGate) registerResources(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		Name:        "Confirmation Log",
+		Description: fmt.Sprintf("The last %d decisions of the confirmation gate, oldest first", maxConfirmDecisions),
+		MIMEType:    "application/json",
+		URI:         "confirmations://log",
+	}, g.logResourceHandler)
+}
+
+func (g *confirmGate) logResourceHandler(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	g.mu.Lock()
+	decisions := slices.Clone(g.decisions)
+	g.mu.Unlock()
+	if decisions == nil {
+		decisions = []confirmDecision{}
+	}
+
+	data, err := json.MarshalIndent(decisions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      req.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}, nil
+}
+
+// clientSupportsElicitation reports whether the client can show forms. An
+// empty elicitation capability means forms only.
+func clientSupportsElicitation(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return false
+	}
+	e := params.Capabilities.Elicitation
+	return e.Form != nil || e.URL == nil
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestConfirmMessageTruncatesArguments(t *testing.T) {
+	args, err := json.Marshal(map[string]string{"text": strings.Repeat("日本語", 100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := confirmMessage(&mcp.Tool{Name: "write"}, "destructive", args)
+	if !utf8.ValidString(msg) {
+		t.Errorf("message isn't valid UTF-8: %q", msg)
+	}
+	_, shown, _ := strings.Cut(msg, "Arguments: ")
+	if len(shown) > 500 || !strings.HasSuffix(shown, "...") {
+		t.Errorf("arguments shown as %d bytes %q, want at most 500 ending in ...", len(shown), shown)
+	}
+}
+
+// confirmLog reads confirmations://log.
+func confirmLog(t *testing.T, cs *mcp.ClientSession) []confirmDecision {
+	t.Helper()
+	res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "confirmations://log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decisions []confirmDecision
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &decisions); err != nil {
+		t.Fatal(err)
+	}
+	return decisions
+}
+
+func setConfirmPolicy(t *testing.T, p ConfirmPolicy) {
+	SetConfirmPolicy(p)
+	t.Cleanup(func() { SetConfirmPolicy(ConfirmPolicy{}) })
+}
+
+// resetConversation calls the destructive reset_conversation. Once past the
+// gate, it fails with not_found: there is no such conversation.
+func resetConversation(t *testing.T, cs *mcp.ClientSession) ToolError {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "reset_conversation", Arguments: map[string]any{"conversationId": "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return toolError(t, res)
+}
+
+func TestConfirmGateAnswers(t *testing.T) {
+	tests := []struct {
+		name      string
+		answer    *mcp.ElicitResult
+		code      ErrorCode
+		retryable bool
+		outcome   string
+	}{
+		{"allowed", &mcp.ElicitResult{Action: "accept", Content: map[string]any{"allow": true}}, CodeNotFound, false, outcomeConfirmed},
+		{"not allowed", &mcp.ElicitResult{Action: "accept", Content: map[string]any{"allow": false}}, CodePermissionDenied, false, outcomeRejected},
+		{"declined", &mcp.ElicitResult{Action: "decline"}, CodePermissionDenied, false, outcomeDeclined},
+		{"cancelled", &mcp.ElicitResult{Action: "cancel"}, CodePermissionDenied, true, outcomeCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var asked []string
+			cs := connectAnswering(t, func(params *mcp.ElicitParams) *mcp.ElicitResult {
+				asked = append(asked, params.Message)
+				return tt.answer
+			})
+			e := resetConversation(t, cs)
+			if e.Code != tt.code || e.Retryable != tt.retryable {
+				t.Errorf("error = %+v, want code %s, retryable %v", e, tt.code, tt.retryable)
+			}
+			if e.Code == CodePermissionDenied && e.Details["outcome"] != tt.outcome {
+				t.Errorf("details = %v, want outcome %s", e.Details, tt.outcome)
+			}
+			if len(asked) != 1 || !strings.Contains(asked[0], "reset_conversation") || !strings.Contains(asked[0], "may delete or overwrite data") {
+				t.Errorf("asked %q, want one confirmation of reset_conversation", asked)
+			}
+
+			log := confirmLog(t, cs)
+			if len(log) != 1 {
+				t.Fatalf("log = %+v, want one decision", log)
+			}
+			d := log[0]
+			if d.Tool != "reset_conversation" || d.Why != "destructive" || d.Outcome != tt.outcome || d.Allowed != (tt.outcome == outcomeConfirmed) {
+				t.Errorf("decision = %+v, want %s", d, tt.outcome)
+			}
+		})
+	}
+}
+
+func TestConfirmGateFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback ConfirmFallback
+		code     ErrorCode
+	}{
+		{"default", "", CodeUnsupportedCapability},
+		{"deny", ConfirmDeny, CodeUnsupportedCapability},
+		{"allow", ConfirmAllow, CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfirmPolicy(t, ConfirmPolicy{Fallback: tt.fallback})
+			cs := connect(t, NewServer(), nil) // Can't elicit
+			if e := resetConversation(t, cs); e.Code != tt.code {
+				t.Errorf("code = %s, want %s", e.Code, tt.code)
+			}
+			log := confirmLog(t, cs)
+			if len(log) != 1 || log[0].Outcome != outcomeFallback || log[0].Allowed != (tt.fallback == ConfirmAllow) {
+				t.Errorf("log = %+v, want one fallback decision", log)
+			}
+		})
+	}
+}
+
+func TestConfirmPolicyFromEnv(t *testing.T) {
+	t.Setenv("MCP_CONFIRM_TOOLS", " enable_toolset, ,grep ")
+	t.Setenv("MCP_CONFIRM_FALLBACK", "allow")
+	p, err := ConfirmPolicyFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(p.Tools, []string{"enable_toolset", "grep"}) || p.Fallback != ConfirmAllow {
+		t.Errorf("policy = %+v", p)
+	}
+
+	t.Setenv("MCP_CONFIRM_FALLBACK", "ask")
+	if _, err := ConfirmPolicyFromEnv(); err == nil {
+		t.Error("MCP_CONFIRM_FALLBACK=ask accepted")
+	}
+}
+
+func TestConfirmGatePolicyTools(t *testing.T) {
+	ctx := context.Background()
+	enable := &mcp.CallToolParams{Name: "enable_toolset", Arguments: map[string]any{"toolset": "calculator"}}
+
+	// enable_toolset isn't destructive, so it runs unasked...
+	cs := connectAnswering(t, func(*mcp.ElicitParams) *mcp.ElicitResult {
+		t.Error("asked to confirm enable_toolset without a policy")
+		return &mcp.ElicitResult{Action: "decline"}
+	})
+	if res, err := cs.CallTool(ctx, enable); err != nil || res.IsError {
+		t.Fatalf("enable_toolset = %+v, %v", res, err)
+	}
+
+	// ...unless the policy names it.
+	setConfirmPolicy(t, ConfirmPolicy{Tools: []string{"enable_toolset"}})
+	var asked int
+	cs = connectAnswering(t, func(params *mcp.ElicitParams) *mcp.ElicitResult {
+		asked++
+		if strings.Contains(params.Message, "delete or overwrite") {
+			t.Errorf("configured tool described as destructive: %q", params.Message)
+		}
+		return &mcp.ElicitResult{Action: "decline"}
+	})
+	res, err := cs.CallTool(ctx, enable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := toolError(t, res); e.Code != CodePermissionDenied || asked != 1 {
+		t.Errorf("code = %s after %d confirmations, want %s after 1", e.Code, asked, CodePermissionDenied)
+	}
+	if log := confirmLog(t, cs); len(log) != 1 || log[0].Why != "configured" {
+		t.Errorf("log = %+v, want one configured decision", log)
+	}
+}
+
+func TestConfirmGateSkipsReadOnlyTools(t *testing.T) {
+	ctx := context.Background()
+	cs := connectAnswering(t, func(params *mcp.ElicitParams) *mcp.ElicitResult {
+		t.Errorf("asked to confirm a read-only tool: %q", params.Message)
+		return &mcp.ElicitResult{Action: "decline"}
+	})
+	for _, params := range []*mcp.CallToolParams{
+		{Name: "hello", Arguments: map[string]any{"name": "Ada"}},
+		{Name: "list_items"},
+		{Name: "list_conversations"},
+		{Name: "list_toolsets"},
+	} {
+		res, err := cs.CallTool(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError {
+			t.Errorf("%s failed: %+v", params.Name, res.Content)
+		}
+	}
+	if log := confirmLog(t, cs); len(log) != 0 {
+		t.Errorf("log = %+v, want no decisions", log)
+	}
+}
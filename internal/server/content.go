@@ -0,0 +1,55 @@
+// content.go — Helpers for tool results that point at resources.
+//
+// LINKS vs EMBEDDED RESOURCES:
+// Besides text, a tool result can carry resources:
+//   - ResourceLink:     A URI the client can read later or show as a link.
+//     Only the URI, name and MIME type are sent.
+//   - EmbeddedResource: The resource's contents inline, as resources/read
+//     would return them.
+//
+// Link when the data is large, changes over time or may not be needed; embed
+// when the model needs it now. get_weather and get_forecast link to
+// weather://{city}, list_items links to each item://{id}, and get_item embeds
+// one.
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolResult returns a result with text followed by more content.
+func toolResult(text string, more ...mcp.Content) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: append([]mcp.Content{&mcp.TextContent{Text: text}}, more...),
+	}
+}
+
+// resourceLink points at one of this server's resources.
+func resourceLink(uri, name, description, mimeType string) *mcp.ResourceLink {
+	return &mcp.ResourceLink{
+		URI:         uri,
+		Name:        name,
+		Description: description,
+		MIMEType:    mimeType,
+	}
+}
+
+// embeddedResource carries contents read from one of this server's resources.
+func embeddedResource(contents *mcp.ResourceContents) *mcp.EmbeddedResource {
+	return &mcp.EmbeddedResource{Resource: contents}
+}
+
+// jsonContents renders v as the indented JSON contents of the resource uri.
+func jsonContents(uri string, v any) (*mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ResourceContents{
+		URI:      uri,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}, nil
+}
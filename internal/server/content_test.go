@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolResult(t *testing.T) {
+	link := resourceLink("item://1", "Widget", "A useful widget", "application/json")
+	contents, err := jsonContents("item://1", map[string]string{"id": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := toolResult("Found it", link, embeddedResource(contents))
+
+	data, err := json.Marshal(res.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"type":"text","text":"Found it"},` +
+		`{"type":"resource_link","mimeType":"application/json","uri":"item://1","name":"Widget","description":"A useful widget"},` +
+		`{"type":"resource","resource":{"uri":"item://1","mimeType":"application/json","text":"{\n  \"id\": \"1\"\n}"}}]`
+	if string(data) != want {
+		t.Errorf("content =\n%s\nwant\n%s", data, want)
+	}
+	if res.IsError || res.StructuredContent != nil {
+		t.Errorf("result = %+v, want only content", res)
+	}
+
+	if res := toolResult("Just text"); len(res.Content) != 1 {
+		t.Errorf("toolResult with no more content has %d items", len(res.Content))
+	}
+}
+
+func TestItemResourceURIsRoundTrip(t *testing.T) {
+	const id = "a b/c"
+	itemsData[id] = ItemData{ID: id, Name: "Escaped"}
+	t.Cleanup(func() { delete(itemsData, id) })
+
+	uri := itemURI(id)
+	if uri != "item://a%20b%2Fc" {
+		t.Errorf("itemURI(%q) = %s", id, uri)
+	}
+	res, err := itemTemplateHandler(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item ItemData
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != id || res.Contents[0].URI != uri {
+		t.Errorf("reading %s gave %s: %+v", uri, res.Contents[0].URI, item)
+	}
+
+	for _, uri := range []string{"item://%zz", "item://nope"} {
+		if _, err := itemTemplateHandler(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}}); err == nil {
+			t.Errorf("reading %s succeeded", uri)
+		}
+	}
+}
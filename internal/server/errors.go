@@ -0,0 +1,179 @@
+// errors.go — Tool errors that clients and models can act on.
+//
+// WHY TYPED ERRORS?
+// A failed tool call still returns a result, with IsError set, so the model
+// sees what went wrong. Text alone leaves it guessing whether to fix its
+// arguments, try again later or give up. So tool handlers fail with a
+// *ToolError, which adds:
+//   - Code:      The kind of failure, one of the Code* constants
+//   - Retryable: Whether the same call may succeed if made again
+//   - Details:   Facts for acting on it, such as the capability that's missing
+//
+// Handlers return the ToolError as their error. The SDK puts its message in
+// the result's text, and the toolErrors middleware records the rest in the
+// result's _meta under ToolErrorMetaKey. (Not in structured content, which
+// must match the tool's output schema.) Any other error a handler returns is
+// recorded as CodeInternal.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolErrorMetaKey is the _meta key of a failed call's ToolError.
+const ToolErrorMetaKey = "io.github.sammorrowdrums.mcp-go-starter/error"
+
+// ErrorCode says what kind of failure a ToolError is.
+type ErrorCode string
+
+const (
+	// CodeInvalidArgument means the arguments are wrong; calling again with
+	// the same ones will fail again.
+	CodeInvalidArgument ErrorCode = "invalid_argument"
+	// CodeNotFound means something the arguments name doesn't exist.
+	CodeNotFound ErrorCode = "not_found"
+	// CodePermissionDenied means the call isn't allowed, for example because
+	// the user didn't confirm it. Details["outcome"] may say why.
+	CodePermissionDenied ErrorCode = "permission_denied"
+	// CodeUnsupportedCapability means the client lacks a capability the tool
+	// needs. Details["capability"] names it.
+	CodeUnsupportedCapability ErrorCode = "unsupported_client_capability"
+	// CodeRateLimited means a service the tool relies on is refusing
+	// requests for now. Details["retryAfterSeconds"] says how long to wait,
+	// if known.
+	CodeRateLimited ErrorCode = "rate_limited"
+	// CodeCancelled means the work was stopped before it finished, for
+	// example because its task was cancelled.
+	CodeCancelled ErrorCode = "cancelled"
+	// CodeInternal is any other failure.
+	CodeInternal ErrorCode = "internal"
+)
+
+// ToolError is a tool call's failure, in a form clients can act on.
+type ToolError struct {
+	Code      ErrorCode      `json:"code"`
+	Message   string         `json:"message"`
+	Retryable bool           `json:"retryable"`
+	Details   map[string]any `json:"details,omitempty"`
+
+	err error // The cause, if any
+}
+
+func (e *ToolError) Error() string { return e.Message }
+
+func (e *ToolError) Unwrap() error { return e.err }
+
+// newToolError formats the message as fmt.Errorf does, keeping any %w
+// operand as the cause.
+func newToolError(code ErrorCode, format string, args ...any) *ToolError {
+	err := fmt.Errorf(format, args...)
+	return &ToolError{
+		Code:      code,
+		Message:   err.Error(),
+		Retryable: code == CodeRateLimited,
+		err:       errors.Unwrap(err),
+	}
+}
+
+func invalidArgument(format string, args ...any) *ToolError {
+	return newToolError(CodeInvalidArgument, format, args...)
+}
+
+func notFound(format string, args ...any) *ToolError {
+	return newToolError(CodeNotFound, format, args...)
+}
+
+func permissionDenied(format string, args ...any) *ToolError {
+	return newToolError(CodePermissionDenied, format, args...)
+}
+
+// unsupportedCapability reports that the client lacks capability, named as
+// in the client's capabilities, such as "sampling" or "elicitation.url".
+func unsupportedCapability(capability, format string, args ...any) *ToolError {
+	return newToolError(CodeUnsupportedCapability, format, args...).withDetail("capability", capability)
+}
+
+// rateLimited reports a refusal to be retried after retryAfter, or when the
+// caller sees fit if it is zero.
+func rateLimited(retryAfter time.Duration, format string, args ...any) *ToolError {
+	e := newToolError(CodeRateLimited, format, args...)
+	if retryAfter > 0 {
+		e = e.withDetail("retryAfterSeconds", int(retryAfter.Round(time.Second)/time.Second))
+	}
+	return e
+}
+
+func cancelled(format string, args ...any) *ToolError {
+	return newToolError(CodeCancelled, format, args...)
+}
+
+func internalError(format string, args ...any) *ToolError {
+	return newToolError(CodeInternal, format, args...)
+}
+
+// withDetail adds a detail to e and returns it.
+func (e *ToolError) withDetail(key string, value any) *ToolError {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// mayRetry marks e as worth retrying and returns it.
+func (e *ToolError) mayRetry() *ToolError {
+	e.Retryable = true
+	return e
+}
+
+// result reports e as a tool result, for code that builds results itself
+// rather than returning an error to the SDK.
+func (e *ToolError) result() *mcp.CallToolResult {
+	res := &mcp.CallToolResult{}
+	res.SetError(e)
+	annotateToolError(res)
+	return res
+}
+
+// asToolError returns err as a *ToolError, treating errors of other types
+// as internal.
+func asToolError(err error) *ToolError {
+	var te *ToolError
+	if errors.As(err, &te) {
+		return te
+	}
+	return &ToolError{Code: CodeInternal, Message: err.Error(), err: err}
+}
+
+// toolErrors is receiving middleware that records the ToolError of every
+// failed tools/call in the result's _meta.
+func toolErrors(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		res, err := next(ctx, method, req)
+		if r, ok := res.(*mcp.CallToolResult); ok && err == nil {
+			annotateToolError(r)
+		}
+		return res, err
+	}
+}
+
+// annotateToolError records the error set on res with SetError in its _meta.
+// Error results built some other way, such as those relayed from gateway
+// upstreams, are left as they are.
+func annotateToolError(res *mcp.CallToolResult) {
+	if !res.IsError || res.GetError() == nil {
+		return
+	}
+	meta := maps.Clone(res.Meta)
+	if meta == nil {
+		meta = mcp.Meta{}
+	}
+	meta[ToolErrorMetaKey] = asToolError(res.GetError())
+	res.Meta = meta
+}
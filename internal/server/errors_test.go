@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolErrorsMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ToolError
+	}{
+		{
+			name: "invalid argument",
+			err:  invalidArgument("bad %q", "x"),
+			want: ToolError{Code: CodeInvalidArgument, Message: `bad "x"`},
+		},
+		{
+			name: "rate limited",
+			err:  rateLimited(30*time.Second, "slow down"),
+			want: ToolError{Code: CodeRateLimited, Message: "slow down", Retryable: true, Details: map[string]any{"retryAfterSeconds": float64(30)}},
+		},
+		{
+			name: "retryable internal",
+			err:  internalError("upstream: %w", errors.New("timeout")).mayRetry(),
+			want: ToolError{Code: CodeInternal, Message: "upstream: timeout", Retryable: true},
+		},
+		{
+			name: "unsupported capability",
+			err:  unsupportedCapability("sampling", "no sampling"),
+			want: ToolError{Code: CodeUnsupportedCapability, Message: "no sampling", Details: map[string]any{"capability": "sampling"}},
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("lookup: %w", notFound("no such city")),
+			want: ToolError{Code: CodeNotFound, Message: "no such city"},
+		},
+		{
+			name: "plain error",
+			err:  errors.New("boom"),
+			want: ToolError{Code: CodeInternal, Message: "boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+			s.AddReceivingMiddleware(toolErrors)
+			mcp.AddTool(s, &mcp.Tool{Name: "fail"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return nil, nil, tt.err
+			})
+			cs := connect(t, s, nil)
+
+			res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "fail"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := toolError(t, res); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("error = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolErrorsMiddlewareKeepsOtherResults(t *testing.T) {
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	s.AddReceivingMiddleware(toolErrors)
+	s.AddTool(&mcp.Tool{Name: "relayed", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// An error result from elsewhere, such as a gateway upstream.
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: "upstream failed"}},
+			Meta:    mcp.Meta{"upstream": "a"},
+		}, nil
+	})
+	mcp.AddTool(s, &mcp.Tool{Name: "ok"}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "fine"}}}, nil, nil
+	})
+	cs := connect(t, s, nil)
+
+	for _, name := range []string{"relayed", "ok"} {
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := res.Meta[ToolErrorMetaKey]; ok {
+			t.Errorf("%s: result has %s: %v", name, ToolErrorMetaKey, res.Meta[ToolErrorMetaKey])
+		}
+	}
+}
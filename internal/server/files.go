@@ -0,0 +1,722 @@
+// files.go — Read-only filesystem tools confined to the client's roots.
+//
+// WHY A SANDBOX?
+// A model choosing paths is an untrusted caller. Every path these tools take
+// must lie inside one of the session's file:// roots (see roots.go) or, if
+// MCP_ALLOWED_DIRS is set, one of those directories instead. Paths are
+// opened through os.Root, which refuses ".." traversal and symlinks that
+// lead outside the directory, so a link inside the workspace can't expose
+// the rest of the disk. Reads and searches are size-limited.
+//
+//   - read_file:      Reads a text file, optionally a range of lines
+//   - list_directory: Lists a directory's entries
+//   - stat:           Reports a file's type, size and modification time
+//   - grep:           Searches files for a regular expression
+//
+// All are read-only. Relative paths are taken from the first directory.
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits on what the file tools read and return.
+const (
+	maxReadFileBytes   = 1 << 20 // Largest file read_file or grep reads
+	maxDirEntries      = 1000    // Most entries list_directory returns
+	maxDirScan         = 100_000 // Most entries list_directory sorts
+	defaultGrepMatches = 100
+	maxGrepMatches     = 1000
+	maxGrepBytes       = 64 << 20 // Most bytes one grep reads in total
+	maxGrepLineLength  = 300      // Longer matching lines are cut
+)
+
+// Directories the file tools use instead of the client's roots; nil means
+// use the roots.
+var allowedDirs []string
+
+// SetAllowedDirs confines the file tools to dirs, which must be absolute,
+// instead of the client's roots. nil restores the roots.
+func SetAllowedDirs(dirs []string) {
+	allowedDirs = dirs
+}
+
+// AllowedDirsFromEnv parses MCP_ALLOWED_DIRS: directories separated by the
+// OS path list separator (":" or ";"). Each must exist. It returns nil when
+// the variable is unset.
+func AllowedDirsFromEnv() ([]string, error) {
+	value := strings.TrimSpace(os.Getenv("MCP_ALLOWED_DIRS"))
+	if value == "" {
+		return nil, nil
+	}
+	var dirs []string
+	for _, dir := range filepath.SplitList(value) {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("MCP_ALLOWED_DIRS: %w", err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("MCP_ALLOWED_DIRS: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("MCP_ALLOWED_DIRS: %s is not a directory", abs)
+		}
+		dirs = append(dirs, abs)
+	}
+	return dirs, nil
+}
+
+// sandbox returns the directories ss's file tools may read.
+func (w *workspace) sandbox(ctx context.Context, ss *mcp.ServerSession) ([]string, error) {
+	if len(allowedDirs) > 0 {
+		return allowedDirs, nil
+	}
+	roots, err := w.roots(ctx, ss)
+	if errors.Is(err, errNoRoots) {
+		return nil, unsupportedCapability("roots", "no directories are available: the client does not share roots and MCP_ALLOWED_DIRS is not set")
+	}
+	if err != nil {
+		return nil, internalError("%w", err).mayRetry()
+	}
+	var dirs []string
+	for _, r := range roots {
+		if dir, err := rootPath(r.URI); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, notFound("no directories are available: the client shares no file:// roots")
+	}
+	return dirs, nil
+}
+
+// resolve finds the directory of dirs that path lies in and path's location
+// relative to it. "" means the first directory. The check is lexical;
+// os.Root catches symlinks that escape.
+func resolve(dirs []string, path string) (dir, rel string, err error) {
+	if path == "" {
+		return dirs[0], ".", nil
+	}
+	if !filepath.IsAbs(path) {
+		rel = filepath.Clean(path)
+		if !filepath.IsLocal(rel) && rel != "." {
+			return "", "", invalidArgument("%s is outside the allowed directories", path)
+		}
+		return dirs[0], rel, nil
+	}
+	path = filepath.Clean(path)
+	for _, d := range dirs {
+		r, err := filepath.Rel(d, path)
+		if err != nil || (!filepath.IsLocal(r) && r != ".") {
+			continue
+		}
+		// Prefer the innermost directory when roots are nested.
+		if dir == "" || len(d) > len(dir) {
+			dir, rel = d, r
+		}
+	}
+	if dir == "" {
+		return "", "", invalidArgument("%s is outside the allowed directories (%s)", path, strings.Join(dirs, ", "))
+	}
+	return dir, rel, nil
+}
+
+// openSandbox resolves path for ss and opens the directory it lies in.
+// Close the returned root when done.
+func (w *workspace) openSandbox(ctx context.Context, ss *mcp.ServerSession, path string) (root *os.Root, dir, rel string, err error) {
+	dirs, err := w.sandbox(ctx, ss)
+	if err != nil {
+		return nil, "", "", err
+	}
+	dir, rel, err = resolve(dirs, path)
+	if err != nil {
+		return nil, "", "", err
+	}
+	root, err = os.OpenRoot(dir)
+	if err != nil {
+		return nil, "", "", fileError(dir, err)
+	}
+	return root, dir, rel, nil
+}
+
+// fileError makes err, from accessing path, readable. Errors other than
+// these are mostly paths that escape their directory through a symlink.
+func fileError(path string, err error) *ToolError {
+	if errors.Is(err, fs.ErrNotExist) {
+		return notFound("%s does not exist", path)
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		return permissionDenied("%s: permission denied", path)
+	}
+	return invalidArgument("%w", err)
+}
+
+// isBinary guesses whether data is binary by looking for a NUL byte early on.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+func fileTypeName(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "directory"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	default:
+		return "other"
+	}
+}
+
+// =============================================================================
+// read_file
+// =============================================================================
+
+type readFileInput struct {
+	Path      string `json:"path" jsonschema:"File to read"`
+	StartLine int    `json:"startLine,omitempty" jsonschema:"First line to return, from 1"`
+	EndLine   int    `json:"endLine,omitempty" jsonschema:"Last line to return"`
+}
+
+// readFileOutput is read_file's structured output.
+type readFileOutput struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	StartLine  int    `json:"startLine"`
+	EndLine    int    `json:"endLine"`
+	TotalLines int    `json:"totalLines"`
+	Content    string `json:"content"`
+}
+
+func (w *workspace) readFileHandler(ctx context.Context, req *mcp.CallToolRequest, input readFileInput) (*mcp.CallToolResult, any, error) {
+	root, dir, rel, err := w.openSandbox(ctx, req.Session, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer root.Close()
+	path := filepath.Join(dir, rel)
+
+	f, err := root.Open(rel)
+	if err != nil {
+		return nil, nil, fileError(path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, fileError(path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil, invalidArgument("%s is a %s, not a file", path, fileTypeName(info.Mode()))
+	}
+	if info.Size() > maxReadFileBytes {
+		return nil, nil, invalidArgument("%s is %d bytes, over the %d byte limit; use grep to find the part you need", path, info.Size(), maxReadFileBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxReadFileBytes))
+	if err != nil {
+		return nil, nil, fileError(path, err)
+	}
+	if isBinary(data) {
+		return nil, nil, invalidArgument("%s looks like a binary file", path)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	start, end := input.StartLine, input.EndLine
+	if start < 1 {
+		start = 1
+	}
+	if end < 1 || end > len(lines) {
+		end = len(lines)
+	}
+	if input.EndLine > 0 && input.EndLine < start {
+		return nil, nil, invalidArgument("endLine %d is before startLine %d", input.EndLine, start)
+	}
+	if start > end && len(lines) > 0 {
+		return nil, nil, invalidArgument("%s has %d lines; startLine %d is past the end", path, len(lines), input.StartLine)
+	}
+
+	out := readFileOutput{
+		Path:       path,
+		Size:       info.Size(),
+		TotalLines: len(lines),
+	}
+	if len(lines) > 0 {
+		out.StartLine, out.EndLine = start, end
+		out.Content = strings.Join(lines[start-1:end], "")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: out.Content},
+		},
+	}, out, nil
+}
+
+// =============================================================================
+// list_directory
+// =============================================================================
+
+type listDirectoryInput struct {
+	Path string `json:"path,omitempty" jsonschema:"Directory to list; defaults to the first root"`
+}
+
+// dirEntry is one entry in list_directory's output.
+type dirEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// listDirectoryOutput is list_directory's structured output.
+type listDirectoryOutput struct {
+	Path      string     `json:"path"`
+	Entries   []dirEntry `json:"entries"`
+	Truncated bool       `json:"truncated"`
+}
+
+func (w *workspace) listDirectoryHandler(ctx context.Context, req *mcp.CallToolRequest, input listDirectoryInput) (*mcp.CallToolResult, any, error) {
+	root, dir, rel, err := w.openSandbox(ctx, req.Session, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer root.Close()
+	path := filepath.Join(dir, rel)
+
+	f, err := root.Open(rel)
+	if err != nil {
+		return nil, nil, fileError(path, err)
+	}
+	defer f.Close()
+	// Sort before truncating, so a large directory shows the first entries
+	// by name rather than whichever the file system returned first. Only
+	// directories larger than maxDirScan fall back to that.
+	entries, err := f.ReadDir(maxDirScan)
+	if err != nil && !errors.Is(err, io.EOF) {
+		if info, statErr := f.Stat(); statErr == nil && !info.IsDir() {
+			return nil, nil, invalidArgument("%s is not a directory", path)
+		}
+		return nil, nil, fileError(path, err)
+	}
+
+	out := listDirectoryOutput{Path: path, Entries: []dirEntry{}}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	if len(entries) > maxDirEntries {
+		entries = entries[:maxDirEntries]
+		out.Truncated = true
+	}
+
+	var text strings.Builder
+	for _, e := range entries {
+		entry := dirEntry{Name: e.Name(), Type: fileTypeName(e.Type())}
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			entry.Size = info.Size()
+		}
+		out.Entries = append(out.Entries, entry)
+
+		switch entry.Type {
+		case "directory":
+			fmt.Fprintf(&text, "%s/\n", entry.Name)
+		case "file":
+			fmt.Fprintf(&text, "%s (%d bytes)\n", entry.Name, entry.Size)
+		default:
+			fmt.Fprintf(&text, "%s (%s)\n", entry.Name, entry.Type)
+		}
+	}
+	if len(entries) == 0 {
+		text.WriteString("(empty directory)\n")
+	}
+	if out.Truncated {
+		fmt.Fprintf(&text, "... only the first %d entries by name are shown\n", maxDirEntries)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, out, nil
+}
+
+// =============================================================================
+// stat
+// =============================================================================
+
+type statInput struct {
+	Path string `json:"path" jsonschema:"File or directory to describe"`
+}
+
+// statOutput is stat's structured output.
+type statOutput struct {
+	Path    string    `json:"path"`
+	Type    string    `json:"type"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	ModTime time.Time `json:"modTime"`
+}
+
+func (w *workspace) statHandler(ctx context.Context, req *mcp.CallToolRequest, input statInput) (*mcp.CallToolResult, any, error) {
+	root, dir, rel, err := w.openSandbox(ctx, req.Session, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer root.Close()
+	path := filepath.Join(dir, rel)
+
+	info, err := root.Stat(rel)
+	if err != nil {
+		return nil, nil, fileError(path, err)
+	}
+	out := statOutput{
+		Path:    path,
+		Type:    fileTypeName(info.Mode()),
+		Size:    info.Size(),
+		Mode:    info.Mode().String(),
+		ModTime: info.ModTime().UTC(),
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("%s: %s, %d bytes, mode %s, modified %s",
+				out.Path, out.Type, out.Size, out.Mode, out.ModTime.Format(time.RFC3339))},
+		},
+	}, out, nil
+}
+
+// =============================================================================
+// grep
+// =============================================================================
+
+type grepInput struct {
+	Pattern         string `json:"pattern" jsonschema:"Regular expression (RE2 syntax)"`
+	Path            string `json:"path,omitempty" jsonschema:"File or directory to search; defaults to the first root"`
+	Include         string `json:"include,omitempty" jsonschema:"Only search files whose name matches this glob, e.g. *.go"`
+	CaseInsensitive bool   `json:"caseInsensitive,omitempty" jsonschema:"Ignore case"`
+	MaxResults      int    `json:"maxResults,omitempty" jsonschema:"Most matches to return"`
+}
+
+// grepMatch is one matching line.
+type grepMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// grepOutput is grep's structured output.
+type grepOutput struct {
+	Matches       []grepMatch `json:"matches"`
+	FilesSearched int         `json:"filesSearched"`
+	Truncated     bool        `json:"truncated"`
+}
+
+// errGrepDone stops the walk once grep has enough.
+var errGrepDone = errors.New("done")
+
+func (w *workspace) grepHandler(ctx context.Context, req *mcp.CallToolRequest, input grepInput) (*mcp.CallToolResult, any, error) {
+	pattern := input.Pattern
+	if input.CaseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, nil, invalidArgument("invalid pattern: %w", err)
+	}
+	if input.Include != "" {
+		if _, err := filepath.Match(input.Include, ""); err != nil {
+			return nil, nil, invalidArgument("invalid include glob: %w", err)
+		}
+	}
+	limit := input.MaxResults
+	if limit <= 0 {
+		limit = defaultGrepMatches
+	}
+	limit = min(limit, maxGrepMatches)
+
+	root, dir, rel, err := w.openSandbox(ctx, req.Session, input.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer root.Close()
+	if _, err := root.Stat(rel); err != nil {
+		return nil, nil, fileError(filepath.Join(dir, rel), err)
+	}
+
+	out := grepOutput{Matches: []grepMatch{}}
+	var scanned int64
+	fsys := root.FS()
+	err = fs.WalkDir(fsys, filepath.ToSlash(rel), func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip what can't be read
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if name != filepath.ToSlash(rel) && (d.Name() == ".git" || d.Name() == "node_modules") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		// Symlinks aren't followed; their targets are searched where they are.
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if input.Include != "" {
+			if ok, _ := filepath.Match(input.Include, d.Name()); !ok {
+				return nil
+			}
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() > maxReadFileBytes {
+			return nil
+		}
+		if scanned+info.Size() > maxGrepBytes {
+			out.Truncated = true
+			return errGrepDone
+		}
+		scanned += info.Size()
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil || isBinary(data) {
+			return nil
+		}
+		out.FilesSearched++
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, maxReadFileBytes)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			if !re.MatchString(text) {
+				continue
+			}
+			if len(out.Matches) == limit {
+				out.Truncated = true
+				return errGrepDone
+			}
+			text = truncate(text, maxGrepLineLength)
+			out.Matches = append(out.Matches, grepMatch{Path: path, Line: line, Text: text})
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errGrepDone) {
+		// Only the context's error gets here; unreadable entries are skipped.
+		return nil, nil, internalError("searching %s: %w", filepath.Join(dir, rel), err).mayRetry()
+	}
+
+	var text strings.Builder
+	for _, m := range out.Matches {
+		fmt.Fprintf(&text, "%s:%d: %s\n", m.Path, m.Line, m.Text)
+	}
+	if len(out.Matches) == 0 {
+		fmt.Fprintf(&text, "No matches in %d file(s).\n", out.FilesSearched)
+	}
+	if out.Truncated {
+		text.WriteString("... stopped early; narrow the search with path or include\n")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, out, nil
+}
+
+// =============================================================================
+// Registration
+// =============================================================================
+
+// registerFileTools registers the file tools of the "workspace" toolset.
+func (w *workspace) registerFileTools(reg *toolRegistry) {
+	annotations := func() *mcp.ToolAnnotations {
+		return &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		}
+	}
+	pathProperty := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "string",
+			"title":       "Path",
+			"description": description + ". Absolute, or relative to the first root.",
+		}
+	}
+
+	addTool(reg, &mcp.Tool{
+		Name:        "read_file",
+		Description: fmt.Sprintf("Read a text file inside the workspace roots (up to %d KiB), optionally only some lines", maxReadFileBytes>>10),
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ReadFileInput",
+			"properties": map[string]interface{}{
+				"path": pathProperty("File to read"),
+				"startLine": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Start line",
+					"description": "First line to return, from 1",
+					"minimum":     1,
+				},
+				"endLine": map[string]interface{}{
+					"type":        "integer",
+					"title":       "End line",
+					"description": "Last line to return (inclusive)",
+					"minimum":     1,
+				},
+			},
+			"required": []string{"path"},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ReadFileOutput",
+			"properties": map[string]interface{}{
+				"path":       map[string]interface{}{"type": "string"},
+				"size":       map[string]interface{}{"type": "integer", "description": "File size in bytes"},
+				"startLine":  map[string]interface{}{"type": "integer"},
+				"endLine":    map[string]interface{}{"type": "integer"},
+				"totalLines": map[string]interface{}{"type": "integer"},
+				"content":    map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"path", "size", "startLine", "endLine", "totalLines", "content"},
+		},
+		Annotations: annotations(),
+	}, w.readFileHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:        "list_directory",
+		Description: "List a directory inside the workspace roots",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ListDirectoryInput",
+			"properties": map[string]interface{}{
+				"path": pathProperty("Directory to list; defaults to the first root"),
+			},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ListDirectoryOutput",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{"type": "string"},
+				"entries": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"name": map[string]interface{}{"type": "string"},
+							"type": map[string]interface{}{"type": "string", "enum": []string{"file", "directory", "symlink", "other"}},
+							"size": map[string]interface{}{"type": "integer"},
+						},
+						"required": []string{"name", "type"},
+					},
+				},
+				"truncated": map[string]interface{}{
+					"type":        "boolean",
+					"description": fmt.Sprintf("Whether only the first %d entries by name are listed", maxDirEntries),
+				},
+			},
+			"required": []string{"path", "entries", "truncated"},
+		},
+		Annotations: annotations(),
+	}, w.listDirectoryHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:        "stat",
+		Description: "Describe a file or directory inside the workspace roots: type, size, mode and modification time",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "StatInput",
+			"properties": map[string]interface{}{
+				"path": pathProperty("File or directory to describe"),
+			},
+			"required": []string{"path"},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "StatOutput",
+			"properties": map[string]interface{}{
+				"path":    map[string]interface{}{"type": "string"},
+				"type":    map[string]interface{}{"type": "string", "enum": []string{"file", "directory", "symlink", "other"}},
+				"size":    map[string]interface{}{"type": "integer"},
+				"mode":    map[string]interface{}{"type": "string"},
+				"modTime": map[string]interface{}{"type": "string", "format": "date-time"},
+			},
+			"required": []string{"path", "type", "size", "mode", "modTime"},
+		},
+		Annotations: annotations(),
+	}, w.statHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:        "grep",
+		Description: "Search text files inside the workspace roots for lines matching a regular expression",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "GrepInput",
+			"properties": map[string]interface{}{
+				"pattern": map[string]interface{}{
+					"type":        "string",
+					"title":       "Pattern",
+					"description": "Regular expression (RE2 syntax)",
+				},
+				"path": pathProperty("File or directory to search; defaults to the first root"),
+				"include": map[string]interface{}{
+					"type":        "string",
+					"title":       "Include",
+					"description": "Only search files whose name matches this glob, e.g. *.go",
+				},
+				"caseInsensitive": map[string]interface{}{
+					"type":        "boolean",
+					"title":       "Case insensitive",
+					"description": "Ignore case",
+				},
+				"maxResults": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Max results",
+					"description": fmt.Sprintf("Most matches to return (default %d)", defaultGrepMatches),
+					"minimum":     1,
+					"maximum":     maxGrepMatches,
+				},
+			},
+			"required": []string{"pattern"},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "GrepOutput",
+			"properties": map[string]interface{}{
+				"matches": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path": map[string]interface{}{"type": "string"},
+							"line": map[string]interface{}{"type": "integer"},
+							"text": map[string]interface{}{"type": "string"},
+						},
+						"required": []string{"path", "line", "text"},
+					},
+				},
+				"filesSearched": map[string]interface{}{"type": "integer"},
+				"truncated": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether the search stopped at a limit",
+				},
+			},
+			"required": []string{"matches", "filesSearched", "truncated"},
+		},
+		Annotations: annotations(),
+	}, w.grepHandler)
+}
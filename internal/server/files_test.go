@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// allowDir gives the file tools dir for the rest of the test.
+func allowDir(t *testing.T, dir string) {
+	SetAllowedDirs([]string{dir})
+	t.Cleanup(func() { SetAllowedDirs(nil) })
+}
+
+func TestListDirectoryTruncatesAfterSorting(t *testing.T) {
+	dir := t.TempDir()
+	// Created in reverse, so the file system is unlikely to return the
+	// first names by chance.
+	for i := maxDirEntries + 10; i >= 0; i-- {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%05d", i)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	allowDir(t, dir)
+	cs := connect(t, NewServer(), nil)
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "list_directory", Arguments: map[string]any{"path": dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("list_directory failed: %s", res.Content[0].(*mcp.TextContent).Text)
+	}
+	out := res.StructuredContent.(map[string]any)
+	entries := out["entries"].([]any)
+	if len(entries) != maxDirEntries || out["truncated"] != true {
+		t.Fatalf("got %d entries, truncated %v; want %d, true", len(entries), out["truncated"], maxDirEntries)
+	}
+	for i, e := range entries {
+		if name, want := e.(map[string]any)["name"], fmt.Sprintf("f%05d", i); name != want {
+			t.Fatalf("entry %d = %v, want %s", i, name, want)
+		}
+	}
+}
+
+func TestGrepCutsLongLinesOnRuneBoundary(t *testing.T) {
+	dir := t.TempDir()
+	line := "match " + strings.Repeat("é", maxGrepLineLength)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte(line+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	allowDir(t, dir)
+	cs := connect(t, NewServer(), nil)
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "grep", Arguments: map[string]any{"pattern": "match", "path": dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("grep failed: %s", res.Content[0].(*mcp.TextContent).Text)
+	}
+	matches := res.StructuredContent.(map[string]any)["matches"].([]any)
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1", len(matches))
+	}
+	text := matches[0].(map[string]any)["text"].(string)
+	if !utf8.ValidString(text) || len(text) > maxGrepLineLength || !strings.HasSuffix(text, "é...") {
+		t.Errorf("text = %q, want valid UTF-8 of at most %d bytes ending in é...", text, maxGrepLineLength)
+	}
+}
+
+func TestFileToolsStayInSandbox(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	write := func(path string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(outside, "secret.txt"), []byte("secret\n"))
+	write(filepath.Join(dir, "big.txt"), bytes.Repeat([]byte("secret\n"), maxReadFileBytes/7+1))
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "link.txt")); err != nil {
+		t.Skipf("can't create symlinks: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+	allowDir(t, dir)
+	cs := connect(t, NewServer(), nil)
+
+	tests := []struct {
+		tool string
+		path string
+		code ErrorCode
+	}{
+		{"read_file", "../" + filepath.Base(outside) + "/secret.txt", CodeInvalidArgument},
+		{"read_file", filepath.Join(outside, "secret.txt"), CodeInvalidArgument},
+		{"read_file", filepath.Join(dir, "..", filepath.Base(outside), "secret.txt"), CodeInvalidArgument},
+		{"read_file", "link.txt", CodeInvalidArgument},
+		{"read_file", "linkdir/secret.txt", CodeInvalidArgument},
+		{"read_file", "big.txt", CodeInvalidArgument},
+		{"list_directory", "..", CodeInvalidArgument},
+		{"list_directory", outside, CodeInvalidArgument},
+		{"list_directory", "linkdir", CodeInvalidArgument},
+		{"grep", "..", CodeInvalidArgument},
+		{"grep", outside, CodeInvalidArgument},
+		{"grep", "link.txt", CodeInvalidArgument},
+		{"grep", "linkdir", CodeInvalidArgument},
+		{"stat", "../" + filepath.Base(outside), CodeInvalidArgument},
+		{"stat", "linkdir/secret.txt", CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool+" "+tt.path, func(t *testing.T) {
+			args := map[string]any{"path": tt.path}
+			if tt.tool == "grep" {
+				args["pattern"] = "secret"
+			}
+			res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: tt.tool, Arguments: args})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e := toolError(t, res); e.Code != tt.code {
+				t.Errorf("code = %s, want %s (%s)", e.Code, tt.code, e.Message)
+			}
+		})
+	}
+
+	// Searching the whole directory skips the links and the big file.
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "grep", Arguments: map[string]any{"pattern": "secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("grep failed: %+v", res.Content)
+	}
+	if out := res.StructuredContent.(map[string]any); len(out["matches"].([]any)) != 0 {
+		t.Errorf("grep found %v", out["matches"])
+	}
+}
+
+func TestFileToolsPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read anything")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "private.txt"), []byte("x"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+	allowDir(t, dir)
+	cs := connect(t, NewServer(), nil)
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "read_file", Arguments: map[string]any{"path": "private.txt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := toolError(t, res); e.Code != CodePermissionDenied {
+		t.Errorf("code = %s, want %s", e.Code, CodePermissionDenied)
+	}
+}
+
+func TestGrepCancelled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("match\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	allowDir(t, dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := newWorkspace().grepHandler(ctx, &mcp.CallToolRequest{}, grepInput{Pattern: "match"})
+	if e := asToolError(err); e.Code != CodeInternal || !e.Retryable || !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %#v, want a retryable internal error wrapping context.Canceled", err)
+	}
+}
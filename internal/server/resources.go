@@ -15,8 +15,12 @@ package server
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -66,6 +70,42 @@ func registerResources(server *mcp.Server) {
 		MIMEType:    "application/json",
 		URITemplate: "item://{id}",
 	}, itemTemplateHandler)
+
+	// Tools link here (see content.go). City names are percent-encoded, as in
+	// weather://New%20York.
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "Current Weather",
+		Description: "Current weather for a city, in Celsius",
+		MIMEType:    "application/json",
+		URITemplate: "weather://{city}",
+	}, weatherTemplateHandler)
+
+	// Binary resources carry base64 Blob contents instead of Text.
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "Icon",
+		Description: "One of the server's 256x256 PNG icons (" + strings.Join(iconNames(), ", ") + ")",
+		MIMEType:    "image/png",
+		URITemplate: "icon://{name}",
+	}, iconTemplateHandler)
+}
+
+// icons maps icon:// names to the data URIs in icons.go.
+var icons = map[string]string{
+	"waving_hand":      WAVING_HAND_ICON,
+	"sun_behind_cloud": SUN_BEHIND_CLOUD_ICON,
+	"robot":            ROBOT_ICON,
+	"hourglass":        HOURGLASS_ICON,
+	"package":          PACKAGE_ICON,
+	"abacus":           ABACUS_ICON,
+}
+
+func iconNames() []string {
+	names := make([]string, 0, len(icons))
+	for name := range icons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func aboutResourceHandler(_ context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
@@ -131,21 +171,82 @@ func greetingTemplateHandler(_ context.Context, req *mcp.ReadResourceRequest) (*
 }
 
 func itemTemplateHandler(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	id := extractParam(req.Params.URI, "item://")
+	id, err := url.PathUnescape(extractParam(req.Params.URI, "item://"))
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
 
 	item, ok := itemsData[id]
 	if !ok {
-		return nil, fmt.Errorf("item not found: %s", id)
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	contents, err := itemContents(item)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{contents},
+	}, nil
+}
+
+// itemURI is the URI of an item's resource.
+func itemURI(id string) string {
+	return "item://" + url.PathEscape(id)
+}
+
+// itemContents is what reading an item's resource returns; get_item embeds it.
+func itemContents(item ItemData) (*mcp.ResourceContents, error) {
+	return jsonContents(itemURI(item.ID), item)
+}
+
+func weatherTemplateHandler(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	city, err := url.PathUnescape(extractParam(req.Params.URI, "weather://"))
+	if err != nil || city == "" {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	weather, err := weatherProvider.CurrentWeather(ctx, city)
+	if errors.Is(err, ErrUnknownCity) {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("weather for %s: %w", city, err)
+	}
+	weather.Temperature, _ = convertTemperature(weather.Temperature, "celsius") // Whole degrees, as get_weather reports
+
+	contents, err := jsonContents(req.Params.URI, weather)
+	if err != nil {
+		return nil, err
 	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{contents},
+	}, nil
+}
+
+// weatherURI is the URI of a city's current weather resource.
+func weatherURI(city string) string {
+	return "weather://" + url.PathEscape(city)
+}
 
-	jsonBytes, _ := json.MarshalIndent(item, "", "  ")
+func iconTemplateHandler(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	name := extractParam(req.Params.URI, "icon://")
+
+	icon, ok := icons[name]
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(icon, "data:image/png;base64,"))
+	if err != nil {
+		return nil, fmt.Errorf("decoding icon %s: %w", name, err)
+	}
 
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
 				URI:      req.Params.URI,
-				MIMEType: "application/json",
-				Text:     string(jsonBytes),
+				MIMEType: "image/png",
+				Blob:     data,
 			},
 		},
 	}, nil
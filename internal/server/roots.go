@@ -0,0 +1,354 @@
+// roots.go — The client's roots: the directories it lets this server work in.
+//
+// WHAT ARE ROOTS?
+// Roots are file:// URIs a client exposes to a server, usually the folders
+// open in the user's workspace. The server asks for them with roots/list;
+// clients that declare roots.listChanged send notifications/roots/list_changed
+// when the user opens or closes folders.
+//
+// Each session's roots are fetched once the session is initialized, cached,
+// and fetched again after every change notification. They are available as:
+//   - list_roots:  Tool reporting the roots, with local paths
+//   - roots://list: The same list as a resource
+//
+// The file tools in files.go are confined to them.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// rootsTimeout bounds fetches made in the background, after initialization
+// or a change notification.
+const rootsTimeout = 10 * time.Second
+
+// errNoRoots means the client didn't declare the roots capability.
+var errNoRoots = errors.New("the client does not share roots")
+
+// workspace caches each session's roots.
+type workspace struct {
+	server *mcp.Server
+
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*sessionRoots
+}
+
+// sessionRoots is one session's cached roots.
+type sessionRoots struct {
+	gen       int // Bumped by each change notification
+	fetched   bool
+	roots     []*mcp.Root
+	updatedAt time.Time
+}
+
+type listRootsInput struct {
+	Refresh bool `json:"refresh,omitempty" jsonschema:"Ask the client again instead of using the cached list"`
+}
+
+// rootInfo describes one root in list_roots' output.
+type rootInfo struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"` // Local path, for file:// roots
+}
+
+// rootList is list_roots' structured output and roots://list's content.
+type rootList struct {
+	Supported bool       `json:"supported"`
+	Roots     []rootInfo `json:"roots"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+func newWorkspace() *workspace {
+	return &workspace{sessions: make(map[*mcp.ServerSession]*sessionRoots)}
+}
+
+// initialized prefetches the roots of a new session.
+func (w *workspace) initialized(ctx context.Context, req *mcp.InitializedRequest) {
+	w.refresh(ctx, req.Session)
+}
+
+// rootsChanged drops a session's cached roots and fetches them again.
+func (w *workspace) rootsChanged(ctx context.Context, req *mcp.RootsListChangedRequest) {
+	w.mu.Lock()
+	e := w.entry(req.Session)
+	e.gen++
+	e.fetched = false
+	w.mu.Unlock()
+	w.refresh(ctx, req.Session)
+}
+
+// refresh fetches ss's roots in the background. Notification handlers must
+// not wait for the client, whose reply would queue behind the notification.
+func (w *workspace) refresh(ctx context.Context, ss *mcp.ServerSession) {
+	if !clientSupportsRoots(ss) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rootsTimeout)
+		defer cancel()
+		_, _ = w.fetch(ctx, ss)
+	}()
+}
+
+// roots returns ss's roots, from the cache when they are current.
+func (w *workspace) roots(ctx context.Context, ss *mcp.ServerSession) ([]*mcp.Root, error) {
+	if !clientSupportsRoots(ss) {
+		return nil, errNoRoots
+	}
+	w.mu.Lock()
+	e := w.entry(ss)
+	if e.fetched {
+		roots := slices.Clone(e.roots)
+		w.mu.Unlock()
+		return roots, nil
+	}
+	w.mu.Unlock()
+	return w.fetch(ctx, ss)
+}
+
+// fetch asks the client for ss's roots and caches them, unless they changed
+// again meanwhile.
+func (w *workspace) fetch(ctx context.Context, ss *mcp.ServerSession) ([]*mcp.Root, error) {
+	w.mu.Lock()
+	e := w.entry(ss)
+	gen := e.gen
+	w.mu.Unlock()
+
+	res, err := ss.ListRoots(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing roots: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if e.gen == gen {
+		e.roots = res.Roots
+		e.fetched = true
+		e.updatedAt = time.Now()
+	}
+	return slices.Clone(res.Roots), nil
+}
+
+// entry returns ss's cache entry, creating it (and forgetting sessions that
+// have ended) if needed. w.mu must be held.
+func (w *workspace) entry(ss *mcp.ServerSession) *sessionRoots {
+	if e, ok := w.sessions[ss]; ok {
+		return e
+	}
+	if w.server != nil {
+		live := make(map[*mcp.ServerSession]bool)
+		for s := range w.server.Sessions() {
+			live[s] = true
+		}
+		for s := range w.sessions {
+			if !live[s] {
+				delete(w.sessions, s)
+			}
+		}
+	}
+	e := &sessionRoots{}
+	w.sessions[ss] = e
+	return e
+}
+
+// list describes ss's roots; refresh skips the cache.
+func (w *workspace) list(ctx context.Context, ss *mcp.ServerSession, refresh bool) (rootList, error) {
+	var roots []*mcp.Root
+	var err error
+	if refresh && clientSupportsRoots(ss) {
+		roots, err = w.fetch(ctx, ss)
+	} else {
+		roots, err = w.roots(ctx, ss)
+	}
+	if errors.Is(err, errNoRoots) {
+		return rootList{Roots: []rootInfo{}}, nil
+	}
+	if err != nil {
+		return rootList{}, err
+	}
+
+	list := rootList{Supported: true, Roots: []rootInfo{}}
+	for _, r := range roots {
+		info := rootInfo{URI: r.URI, Name: r.Name}
+		if path, err := rootPath(r.URI); err == nil {
+			info.Path = path
+		}
+		list.Roots = append(list.Roots, info)
+	}
+	w.mu.Lock()
+	if e := w.sessions[ss]; e != nil && e.fetched {
+		updatedAt := e.updatedAt
+		list.UpdatedAt = &updatedAt
+	}
+	w.mu.Unlock()
+	return list, nil
+}
+
+// rootPath converts a file:// root URI to a local path.
+func rootPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("root %s is not a file:// URI", uri)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("root %s is on another host", uri)
+	}
+	path := u.Path
+	// file:///C:/src has the path /C:/src.
+	if runtime.GOOS == "windows" && len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	if path == "" {
+		return "", fmt.Errorf("root %s has no path", uri)
+	}
+	return filepath.Clean(filepath.FromSlash(path)), nil
+}
+
+func clientSupportsRoots(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.RootsV2 != nil
+}
+
+var rootInfoSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"uri": map[string]interface{}{
+			"type":        "string",
+			"description": "Root URI, normally file://",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Display name the client gave the root",
+		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Local path of a file:// root",
+		},
+	},
+	"required": []string{"uri"},
+}
+
+// registerTools registers the "workspace" toolset.
+func (w *workspace) registerTools(reg *toolRegistry) {
+	addTool(reg, &mcp.Tool{
+		Name:        "list_roots",
+		Description: "List the workspace roots (directories) the client shares with this server",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ListRootsInput",
+			"properties": map[string]interface{}{
+				"refresh": map[string]interface{}{
+					"type":        "boolean",
+					"title":       "Refresh",
+					"description": "Ask the client again instead of using the cached list",
+					// No "default": the SDK can't apply defaults to a call
+					// that sends no arguments.
+				},
+			},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "RootList",
+			"properties": map[string]interface{}{
+				"supported": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether the client shares roots at all",
+				},
+				"roots": map[string]interface{}{
+					"type":  "array",
+					"items": rootInfoSchema,
+				},
+				"updatedAt": map[string]interface{}{
+					"type":        "string",
+					"format":      "date-time",
+					"description": "When the roots were last fetched from the client",
+				},
+			},
+			"required": []string{"supported", "roots"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, w.listRootsHandler)
+
+	w.registerFileTools(reg)
+}
+
+// registerResources registers roots://list. It stays available while the
+// workspace toolset is disabled.
+func (w *workspace) registerResources(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		Name:        "Client Roots",
+		Description: "The workspace roots the client shares with this server",
+		MIMEType:    "application/json",
+		URI:         "roots://list",
+	}, w.rootsResourceHandler)
+}
+
+func (w *workspace) listRootsHandler(ctx context.Context, req *mcp.CallToolRequest, input listRootsInput) (*mcp.CallToolResult, any, error) {
+	list, err := w.list(ctx, req.Session, input.Refresh)
+	if err != nil {
+		return nil, nil, internalError("%w", err).mayRetry()
+	}
+
+	var text strings.Builder
+	switch {
+	case !list.Supported:
+		text.WriteString("The client does not share roots with this server.")
+	case len(list.Roots) == 0:
+		text.WriteString("The client shares no roots.")
+	default:
+		fmt.Fprintf(&text, "The client shares %d root(s):\n", len(list.Roots))
+		for _, r := range list.Roots {
+			line := r.URI
+			if r.Name != "" {
+				line = fmt.Sprintf("%s (%s)", r.Name, r.URI)
+			}
+			fmt.Fprintf(&text, "- %s\n", line)
+		}
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, list, nil
+}
+
+func (w *workspace) rootsResourceHandler(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	list, err := w.list(ctx, req.Session, false)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      req.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(data),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectWorkspace connects client to a server with only the workspace's
+// tools and resources. Unlike NewServer's, it doesn't prefetch roots when the
+// session starts, so the test sees every roots/list.
+func connectWorkspace(t *testing.T, client *mcp.Client) (*workspace, *mcp.ServerSession, *mcp.ClientSession) {
+	t.Helper()
+	ctx := context.Background()
+	w := newWorkspace()
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, &mcp.ServerOptions{
+		RootsListChangedHandler: w.rootsChanged,
+	})
+	w.server = s
+	w.registerTools(&toolRegistry{server: s})
+	w.registerResources(s)
+
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := s.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return w, ss, cs
+}
+
+// rootsClient returns a client sharing roots, and a count of the roots/list
+// requests it has answered.
+func rootsClient(roots ...*mcp.Root) (*mcp.Client, *atomic.Int32) {
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	client.AddRoots(roots...)
+	var lists atomic.Int32
+	client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "roots/list" {
+				lists.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	return client, &lists
+}
+
+func rootURIs(roots []*mcp.Root) []string {
+	var uris []string
+	for _, r := range roots {
+		uris = append(uris, r.URI)
+	}
+	return uris
+}
+
+func TestWorkspaceCachesRoots(t *testing.T) {
+	ctx := context.Background()
+	client, lists := rootsClient(&mcp.Root{URI: "file:///work"})
+	w, ss, _ := connectWorkspace(t, client)
+
+	for range 3 {
+		roots, err := w.roots(ctx, ss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uris := rootURIs(roots); !slices.Equal(uris, []string{"file:///work"}) {
+			t.Errorf("roots = %v, want file:///work", uris)
+		}
+	}
+	if n := lists.Load(); n != 1 {
+		t.Errorf("%d roots/list requests, want 1", n)
+	}
+}
+
+func TestWorkspaceRefetchesAfterChange(t *testing.T) {
+	ctx := context.Background()
+	client, lists := rootsClient(&mcp.Root{URI: "file:///work"})
+	w, ss, _ := connectWorkspace(t, client)
+	if _, err := w.roots(ctx, ss); err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding a root sends notifications/roots/list_changed.
+	client.AddRoots(&mcp.Root{URI: "file:///more"})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		roots, err := w.roots(ctx, ss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slices.Equal(rootURIs(roots), []string{"file:///more", "file:///work"}) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("roots = %v after the change, want both", rootURIs(roots))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := lists.Load(); n < 2 {
+		t.Errorf("%d roots/list requests, want the roots fetched again", n)
+	}
+}
+
+func TestWorkspaceIgnoresStaleFetch(t *testing.T) {
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	client.AddRoots(&mcp.Root{URI: "file:///new"})
+	// The first roots/list is held, then answered with the roots from
+	// before the change.
+	started, release := make(chan struct{}), make(chan struct{})
+	var lists atomic.Int32
+	client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "roots/list" && lists.Add(1) == 1 {
+				close(started)
+				<-release
+				return &mcp.ListRootsResult{Roots: []*mcp.Root{{URI: "file:///old"}}}, nil
+			}
+			return next(ctx, method, req)
+		}
+	})
+	w, ss, _ := connectWorkspace(t, client)
+
+	stale := make(chan []*mcp.Root)
+	go func() {
+		roots, err := w.fetch(ctx, ss)
+		if err != nil {
+			t.Error(err)
+		}
+		stale <- roots
+	}()
+	<-started
+	// What rootsChanged does before fetching again in the background.
+	w.mu.Lock()
+	e := w.entry(ss)
+	e.gen++
+	e.fetched = false
+	w.mu.Unlock()
+	close(release)
+	if uris := rootURIs(<-stale); !slices.Equal(uris, []string{"file:///old"}) {
+		t.Fatalf("stale fetch returned %v", uris)
+	}
+
+	roots, err := w.roots(ctx, ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uris := rootURIs(roots); !slices.Equal(uris, []string{"file:///new"}) {
+		t.Errorf("roots = %v, want file:///new: the stale fetch was cached", uris)
+	}
+}
+
+func TestListRootsTool(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	uri := "file://" + filepath.ToSlash(dir)
+
+	client, lists := rootsClient(&mcp.Root{URI: uri, Name: "work"}, &mcp.Root{URI: "https://example.com/repo"})
+	_, _, cs := connectWorkspace(t, client)
+	for _, refresh := range []bool{false, false, true} {
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "list_roots", Arguments: map[string]any{"refresh": refresh}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError {
+			t.Fatalf("list_roots failed: %+v", res.Content)
+		}
+		var list rootList
+		raw, _ := json.Marshal(res.StructuredContent)
+		if err := json.Unmarshal(raw, &list); err != nil {
+			t.Fatal(err)
+		}
+		want := []rootInfo{{URI: uri, Name: "work", Path: dir}, {URI: "https://example.com/repo"}}
+		if !list.Supported || !slices.Equal(list.Roots, want) || list.UpdatedAt == nil {
+			t.Errorf("list_roots = %+v, want %+v", list, want)
+		}
+	}
+	// Once for the first call, and again for refresh.
+	if n := lists.Load(); n != 2 {
+		t.Errorf("%d roots/list requests, want 2", n)
+	}
+
+	// A client without roots gets an answer, not an error.
+	noRoots := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, &mcp.ClientOptions{Capabilities: &mcp.ClientCapabilities{}})
+	_, _, cs = connectWorkspace(t, noRoots)
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "list_roots"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := res.StructuredContent.(map[string]any); res.IsError || out["supported"] != false {
+		t.Errorf("list_roots without roots = %+v", res)
+	}
+}
+
+func TestRootsResource(t *testing.T) {
+	client, _ := rootsClient(&mcp.Root{URI: "file:///work", Name: "work"})
+	_, _, cs := connectWorkspace(t, client)
+	res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "roots://list"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Contents) != 1 || res.Contents[0].MIMEType != "application/json" {
+		t.Fatalf("contents = %+v", res.Contents)
+	}
+	var list rootList
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &list); err != nil {
+		t.Fatal(err)
+	}
+	if !list.Supported || len(list.Roots) != 1 || list.Roots[0].Name != "work" || list.Roots[0].Path != filepath.FromSlash("/work") {
+		t.Errorf("roots://list = %+v", list)
+	}
+}
+
+func TestRootPath(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string // "" for an error
+	}{
+		{uri: "file:///work/src", want: "/work/src"},
+		{uri: "file://localhost/work", want: "/work"},
+		{uri: "file:///work/a%20b/../c", want: "/work/c"},
+		{uri: "https://example.com/work"},
+		{uri: "/work"},
+		{uri: "file://server/share"},
+		{uri: "file://"},
+		{uri: "%zz"},
+	}
+	for _, tt := range tests {
+		got, err := rootPath(tt.uri)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("rootPath(%q) = %q, want an error", tt.uri, got)
+			}
+			continue
+		}
+		if want := filepath.FromSlash(tt.want); err != nil || got != want {
+			t.Errorf("rootPath(%q) = %q, %v; want %q", tt.uri, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,703 @@
+// sampling.go — Tools that use MCP sampling.
+//
+// WHAT IS SAMPLING?
+// Sampling inverts the usual flow: instead of the AI calling a tool, the tool
+// asks the *client's* LLM for a completion (sampling/createMessage). The
+// server never needs its own model or API key; the client stays in control
+// of which model runs and may show the request to the user first.
+//
+// Everything in the request is a hint the client may adjust or ignore:
+// system prompt, temperature, stop sequences, model preferences and context.
+//
+// CONVERSATIONS:
+// Sampling is stateless — each createMessage carries the full message list.
+// Passing a conversationId to ask_llm makes the server remember each turn and
+// resend the history, so successive calls form one conversation. History is
+// capped (oldest turns are dropped first) and can be listed, exported and
+// reset with list_conversations, export_conversation and reset_conversation.
+// Conversations belong to the server, so in HTTP mode each session has its own;
+// past maxConversations, the least recently used is forgotten.
+//
+// MULTIMODAL:
+// Sampling messages may carry images and audio as well as text. ask_llm's
+// attachments are sent as user messages ahead of the prompt, either inline
+// (base64) or read from one of this server's resources (e.g. icon://robot).
+// Image and audio replies are returned as image and audio tool content.
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Limits on a conversation's history, including the new prompt. Tokens are
+// estimated at four characters each.
+const (
+	maxConversationMessages = 20
+	maxConversationTokens   = 4000
+)
+
+// maxConversations caps how many conversations a server remembers.
+const maxConversations = 100
+
+// maxAttachmentSize caps the decoded size of each ask_llm attachment.
+const maxAttachmentSize = 1 << 20
+
+type askLLMInput struct {
+	Prompt           string               `json:"prompt" jsonschema:"The question or prompt to send to the LLM"`
+	ConversationID   string               `json:"conversationId,omitempty" jsonschema:"Continue this conversation"`
+	MaxTokens        int                  `json:"maxTokens,omitempty" jsonschema:"Maximum tokens in response"`
+	SystemPrompt     string               `json:"systemPrompt,omitempty" jsonschema:"System prompt for the LLM"`
+	Temperature      *float64             `json:"temperature,omitempty" jsonschema:"Sampling temperature"`
+	StopSequences    []string             `json:"stopSequences,omitempty" jsonschema:"Sequences that end generation"`
+	ModelPreferences *modelPreferencesArg `json:"modelPreferences,omitempty" jsonschema:"Preferences for choosing a model"`
+	IncludeContext   string               `json:"includeContext,omitempty" jsonschema:"enum=none,enum=thisServer,enum=allServers"`
+	Attachments      []attachmentArg      `json:"attachments,omitempty" jsonschema:"Images or audio to send with the prompt"`
+}
+
+// attachmentArg is an image or audio input to ask_llm, given either inline
+// as base64 data or as the URI of one of this server's resources.
+type attachmentArg struct {
+	Type     string `json:"type" jsonschema:"enum=image,enum=audio"`
+	Data     string `json:"data,omitempty"`
+	MIMEType string `json:"mimeType,omitempty"`
+	URI      string `json:"uri,omitempty"`
+}
+
+// modelPreferencesArg is the tool-facing form of mcp.ModelPreferences, with
+// hints as plain model names.
+type modelPreferencesArg struct {
+	Hints                []string `json:"hints,omitempty"`
+	CostPriority         float64  `json:"costPriority,omitempty"`
+	SpeedPriority        float64  `json:"speedPriority,omitempty"`
+	IntelligencePriority float64  `json:"intelligencePriority,omitempty"`
+}
+
+// askLLMResult is ask_llm's structured output.
+type askLLMResult struct {
+	Text           string `json:"text"` // Empty for image and audio replies
+	ContentType    string `json:"contentType"`
+	MIMEType       string `json:"mimeType,omitempty"`
+	Model          string `json:"model"`
+	StopReason     string `json:"stopReason,omitempty"`
+	ConversationID string `json:"conversationId,omitempty"`
+	HistoryLength  int    `json:"historyLength,omitempty"`
+}
+
+type conversationInput struct {
+	ConversationID string `json:"conversationId" jsonschema:"ID of the conversation"`
+}
+
+// conversation is the message history of one conversationId.
+type conversation struct {
+	mu        sync.Mutex // Held for a whole turn, so turns don't interleave
+	messages  []*mcp.SamplingMessage
+	updatedAt time.Time
+
+	lastUse uint64 // Guarded by samplingTools.mu; see samplingTools.uses
+}
+
+// conversationSummary describes a conversation for list_conversations.
+type conversationSummary struct {
+	ConversationID  string    `json:"conversationId"`
+	Messages        int       `json:"messages"`
+	EstimatedTokens int       `json:"estimatedTokens"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type conversationList struct {
+	Conversations []conversationSummary `json:"conversations"`
+}
+
+// conversationExport is export_conversation's structured output.
+type conversationExport struct {
+	ConversationID string                 `json:"conversationId"`
+	Messages       []*mcp.SamplingMessage `json:"messages"`
+}
+
+// samplingTools holds the conversations of one server's sampling tools.
+type samplingTools struct {
+	self *loopback // For reading attachments by URI
+
+	mu            sync.Mutex
+	conversations map[string]*conversation
+	uses          uint64 // Counts conversation uses, ordering them for eviction
+}
+
+func newSamplingTools(self *loopback) *samplingTools {
+	return &samplingTools{self: self, conversations: make(map[string]*conversation)}
+}
+
+// conversation returns the conversation with the given ID. With create set,
+// it creates the conversation if need be, forgetting the least recently used
+// one to stay within maxConversations, and marks it used.
+func (st *samplingTools) conversation(id string, create bool) *conversation {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	c, ok := st.conversations[id]
+	if !create {
+		return c
+	}
+	if !ok {
+		if len(st.conversations) >= maxConversations {
+			st.evictLocked()
+		}
+		c = &conversation{}
+		st.conversations[id] = c
+	}
+	st.uses++
+	c.lastUse = st.uses
+	return c
+}
+
+// evictLocked forgets the least recently used conversation. st.mu must be
+// held.
+func (st *samplingTools) evictLocked() {
+	var oldest string
+	var oldestUse uint64
+	for id, c := range st.conversations {
+		if oldest == "" || c.lastUse < oldestUse {
+			oldest, oldestUse = id, c.lastUse
+		}
+	}
+	delete(st.conversations, oldest)
+}
+
+// estimateTokens roughly sizes a message for the history cap.
+func estimateTokens(m *mcp.SamplingMessage) int {
+	if tc, ok := m.Content.(*mcp.TextContent); ok {
+		return len(tc.Text)/4 + 1
+	}
+	return 100 // Non-text content; clients size these very differently
+}
+
+// trimHistory returns history followed by prompt, dropping the oldest turns
+// of history until the whole fits the caps. A turn is everything up to and
+// including an assistant reply; prompt is always kept.
+func trimHistory(history, prompt []*mcp.SamplingMessage) []*mcp.SamplingMessage {
+	total := 0
+	for _, m := range slices.Concat(history, prompt) {
+		total += estimateTokens(m)
+	}
+	for len(history) > 0 && (len(history)+len(prompt) > maxConversationMessages || total > maxConversationTokens) {
+		for len(history) > 0 {
+			m := history[0]
+			total -= estimateTokens(m)
+			history = history[1:]
+			if m.Role == "assistant" {
+				break
+			}
+		}
+	}
+	return slices.Concat(history, prompt)
+}
+
+// describeContent summarizes non-text content for text output, e.g.
+// "[image/png image]".
+func describeContent(c mcp.Content) string {
+	switch c := c.(type) {
+	case *mcp.TextContent:
+		return c.Text
+	case *mcp.ImageContent:
+		return fmt.Sprintf("[%s image]", c.MIMEType)
+	case *mcp.AudioContent:
+		return fmt.Sprintf("[%s audio]", c.MIMEType)
+	default:
+		return "[non-text content]"
+	}
+}
+
+// attachmentContent turns an attachment into sampling content, reading it
+// from this server's resources if it is given by URI.
+func (st *samplingTools) attachmentContent(ctx context.Context, ss *mcp.ServerSession, a attachmentArg) (mcp.Content, error) {
+	if (a.Data == "") == (a.URI == "") {
+		return nil, invalidArgument("%s attachment needs exactly one of data or uri", a.Type)
+	}
+
+	data, mimeType := []byte(nil), a.MIMEType
+	if a.URI != "" {
+		res, err := st.self.readResource(ctx, ss, a.URI)
+		if err != nil {
+			return nil, notFound("reading %s: %w", a.URI, err)
+		}
+		if len(res.Contents) == 0 || res.Contents[0].Blob == nil {
+			return nil, invalidArgument("%s is not a binary resource", a.URI)
+		}
+		data = res.Contents[0].Blob
+		if mimeType == "" {
+			mimeType = res.Contents[0].MIMEType
+		}
+	} else {
+		if base64.StdEncoding.DecodedLen(len(a.Data)) > maxAttachmentSize+2 {
+			return nil, invalidArgument("%s attachment is over %d bytes", a.Type, maxAttachmentSize)
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(a.Data); err != nil {
+			return nil, invalidArgument("%s attachment: invalid base64 data: %w", a.Type, err)
+		}
+		if mimeType == "" {
+			return nil, invalidArgument("%s attachment: mimeType is required with data", a.Type)
+		}
+	}
+	if !strings.HasPrefix(mimeType, a.Type+"/") {
+		return nil, invalidArgument("%s attachment has MIME type %q", a.Type, mimeType)
+	}
+	if len(data) > maxAttachmentSize {
+		return nil, invalidArgument("%s attachment is over %d bytes", a.Type, maxAttachmentSize)
+	}
+
+	if a.Type == "audio" {
+		return &mcp.AudioContent{Data: data, MIMEType: mimeType}, nil
+	}
+	return &mcp.ImageContent{Data: data, MIMEType: mimeType}, nil
+}
+
+// priorityProperty describes a 0–1 model-selection priority.
+func priorityProperty(title, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "number",
+		"title":       title,
+		"description": description,
+		"minimum":     0,
+		"maximum":     1,
+	}
+}
+
+// registerTools registers the "sampling" toolset.
+func (st *samplingTools) registerTools(reg *toolRegistry) {
+	// ask_llm — Demonstrates MCP sampling: the server asks the *client's* LLM
+	// a question. This inverts the usual flow — instead of the AI calling a tool,
+	// the tool calls the AI. Useful for sub-queries and chain-of-thought.
+	addTool(reg, &mcp.Tool{
+		Name:        "ask_llm",
+		Description: "Ask the connected LLM a question using sampling",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "AskLLMInput",
+			"properties": map[string]interface{}{
+				"prompt": map[string]interface{}{
+					"type":        "string",
+					"title":       "Prompt",
+					"description": "The question or prompt to send to the LLM",
+				},
+				"conversationId": map[string]interface{}{
+					"type":        "string",
+					"title":       "Conversation ID",
+					"description": "Any ID of your choosing; calls with the same ID share a message history",
+				},
+				"maxTokens": map[string]interface{}{
+					"type":        "integer",
+					"title":       "Max Tokens",
+					"description": "Maximum tokens in response",
+					"default":     100,
+				},
+				"systemPrompt": map[string]interface{}{
+					"type":        "string",
+					"title":       "System Prompt",
+					"description": "System prompt for the LLM (the client may modify or omit it)",
+				},
+				"temperature": map[string]interface{}{
+					"type":  "number",
+					"title": "Temperature",
+					// Above 0: CreateMessageParams omits a zero temperature, which
+					// the client would read as "use the default".
+					"description":      "Sampling temperature, above 0; higher is more random. Omit for the model's default, or use e.g. 0.01 for near-deterministic output",
+					"exclusiveMinimum": 0,
+					"maximum":          2,
+				},
+				"stopSequences": map[string]interface{}{
+					"type":        "array",
+					"title":       "Stop Sequences",
+					"description": "Sequences that end generation when produced",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"modelPreferences": map[string]interface{}{
+					"type":        "object",
+					"title":       "Model Preferences",
+					"description": "Hints for which model the client should choose",
+					"properties": map[string]interface{}{
+						"hints": map[string]interface{}{
+							"type":        "array",
+							"title":       "Model Hints",
+							"description": "Model names or families to prefer, in order (e.g. \"claude-3-5-sonnet\", \"sonnet\")",
+							"items":       map[string]interface{}{"type": "string"},
+						},
+						"costPriority":         priorityProperty("Cost Priority", "How much to prioritize low cost (0–1)"),
+						"speedPriority":        priorityProperty("Speed Priority", "How much to prioritize low latency (0–1)"),
+						"intelligencePriority": priorityProperty("Intelligence Priority", "How much to prioritize capability (0–1)"),
+					},
+				},
+				"includeContext": map[string]interface{}{
+					"type":        "string",
+					"title":       "Include Context",
+					"description": "Context from MCP servers to attach to the prompt; needs client support for values other than none",
+					"enum":        []string{"none", "thisServer", "allServers"},
+					"default":     "none",
+				},
+				"attachments": map[string]interface{}{
+					"type":        "array",
+					"title":       "Attachments",
+					"description": "Images or audio sent before the prompt, up to 1 MiB each. Give each as base64 data with a mimeType, or as the URI of one of this server's resources (e.g. icon://robot)",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"type": map[string]interface{}{
+								"type": "string",
+								"enum": []string{"image", "audio"},
+							},
+							"data": map[string]interface{}{
+								"type":            "string",
+								"description":     "Base64-encoded content",
+								"contentEncoding": "base64",
+							},
+							"mimeType": map[string]interface{}{
+								"type":        "string",
+								"description": "e.g. image/png or audio/wav; defaults to the resource's type for uri",
+							},
+							"uri": map[string]interface{}{
+								"type":        "string",
+								"description": "URI of a binary resource on this server",
+							},
+						},
+						"required": []string{"type"},
+					},
+				},
+			},
+			"required": []string{"prompt"},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "AskLLMResult",
+			"properties": map[string]interface{}{
+				"text": map[string]interface{}{
+					"type":        "string",
+					"description": "The LLM's reply; empty if it is an image or audio",
+				},
+				"contentType": map[string]interface{}{
+					"type":        "string",
+					"description": "Type of the reply; images and audio are returned as tool content",
+					"enum":        []string{"text", "image", "audio"},
+				},
+				"mimeType": map[string]interface{}{
+					"type":        "string",
+					"description": "MIME type of an image or audio reply",
+				},
+				"model": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the model that replied",
+				},
+				"stopReason": map[string]interface{}{
+					"type":        "string",
+					"description": "Why generation stopped (e.g. endTurn, stopSequence, maxTokens)",
+				},
+				"conversationId": map[string]interface{}{
+					"type":        "string",
+					"description": "The conversation this turn belongs to",
+				},
+				"historyLength": map[string]interface{}{
+					"type":        "integer",
+					"description": "Messages in the conversation after this turn",
+				},
+			},
+			"required": []string{"text", "contentType", "model"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+		Icons: []mcp.Icon{
+			{
+				Source:   ROBOT_ICON,
+				MIMEType: "image/png",
+				Sizes:    []string{"256x256"},
+			},
+		},
+	}, st.askLLMHandler)
+
+	conversationIDSchema := map[string]interface{}{
+		"type":  "object",
+		"title": "ConversationInput",
+		"properties": map[string]interface{}{
+			"conversationId": map[string]interface{}{
+				"type":        "string",
+				"title":       "Conversation ID",
+				"description": "ID of the conversation",
+			},
+		},
+		"required": []string{"conversationId"},
+	}
+
+	addTool(reg, &mcp.Tool{
+		Name:        "list_conversations",
+		Description: "List ask_llm conversations in this session",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"title":      "ListConversationsInput",
+			"properties": map[string]interface{}{},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ConversationList",
+			"properties": map[string]interface{}{
+				"conversations": map[string]interface{}{
+					"type": "array",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"conversationId":  map[string]interface{}{"type": "string"},
+							"messages":        map[string]interface{}{"type": "integer"},
+							"estimatedTokens": map[string]interface{}{"type": "integer"},
+							"updatedAt":       map[string]interface{}{"type": "string", "format": "date-time"},
+						},
+						"required": []string{"conversationId", "messages", "estimatedTokens", "updatedAt"},
+					},
+				},
+			},
+			"required": []string{"conversations"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, st.listConversationsHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:        "export_conversation",
+		Description: "Export an ask_llm conversation's message history",
+		InputSchema: conversationIDSchema,
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ConversationExport",
+			"properties": map[string]interface{}{
+				"conversationId": map[string]interface{}{"type": "string"},
+				"messages": map[string]interface{}{
+					"type":        "array",
+					"description": "Sampling messages, oldest first",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"role":    map[string]interface{}{"type": "string", "enum": []string{"user", "assistant"}},
+							"content": map[string]interface{}{"type": "object"},
+						},
+						"required": []string{"role", "content"},
+					},
+				},
+			},
+			"required": []string{"conversationId", "messages"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, st.exportConversationHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:        "reset_conversation",
+		Description: "Forget an ask_llm conversation's history",
+		InputSchema: conversationIDSchema,
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(true), // History can't be recovered
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, st.resetConversationHandler)
+
+	st.registerAgentTool(reg)
+}
+
+// askLLMHandler uses MCP sampling: req.Session.CreateMessage sends a prompt
+// to the client's LLM and returns its response. With a conversationId, the
+// prompt is sent after the conversation's history and the turn is recorded.
+func (st *samplingTools) askLLMHandler(ctx context.Context, req *mcp.CallToolRequest, input askLLMInput) (*mcp.CallToolResult, any, error) {
+	maxTokens := input.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 100
+	}
+
+	if !clientSupportsSampling(req.Session) {
+		return nil, nil, unsupportedCapability("sampling", "This client does not support sampling")
+	}
+	// includeContext other than "none" is only allowed if the client says
+	// it supports it.
+	if input.IncludeContext != "" && input.IncludeContext != "none" && !clientSupportsSamplingContext(req.Session) {
+		return nil, nil, unsupportedCapability("sampling.context", "includeContext %q is not supported by this client", input.IncludeContext)
+	}
+
+	var prompt []*mcp.SamplingMessage
+	for _, a := range input.Attachments {
+		content, err := st.attachmentContent(ctx, req.Session, a)
+		if err != nil {
+			return nil, nil, err
+		}
+		prompt = append(prompt, &mcp.SamplingMessage{Role: "user", Content: content})
+	}
+	prompt = append(prompt, &mcp.SamplingMessage{
+		Role:    "user",
+		Content: &mcp.TextContent{Text: input.Prompt},
+	})
+	messages := prompt
+	var conv *conversation
+	if input.ConversationID != "" {
+		conv = st.conversation(input.ConversationID, true)
+		conv.mu.Lock()
+		defer conv.mu.Unlock()
+		messages = trimHistory(conv.messages, prompt)
+	}
+
+	params := &mcp.CreateMessageParams{
+		Messages:       messages,
+		MaxTokens:      int64(maxTokens),
+		SystemPrompt:   input.SystemPrompt,
+		StopSequences:  input.StopSequences,
+		IncludeContext: input.IncludeContext,
+	}
+	if input.Temperature != nil {
+		params.Temperature = *input.Temperature
+	}
+	if prefs := input.ModelPreferences; prefs != nil {
+		params.ModelPreferences = &mcp.ModelPreferences{
+			CostPriority:         prefs.CostPriority,
+			SpeedPriority:        prefs.SpeedPriority,
+			IntelligencePriority: prefs.IntelligencePriority,
+		}
+		for _, hint := range prefs.Hints {
+			params.ModelPreferences.Hints = append(params.ModelPreferences.Hints, &mcp.ModelHint{Name: hint})
+		}
+	}
+
+	result, err := req.Session.CreateMessage(ctx, params)
+	if err != nil {
+		return nil, nil, internalError("Sampling failed: %w", err).mayRetry()
+	}
+
+	out := askLLMResult{Model: result.Model, StopReason: result.StopReason}
+	content := []mcp.Content{
+		&mcp.TextContent{Text: fmt.Sprintf("LLM Response: %s", describeContent(result.Content))},
+	}
+	switch c := result.Content.(type) {
+	case *mcp.TextContent:
+		out.Text, out.ContentType = c.Text, "text"
+	case *mcp.ImageContent:
+		out.ContentType, out.MIMEType = "image", c.MIMEType
+		content = append(content, c)
+	case *mcp.AudioContent:
+		out.ContentType, out.MIMEType = "audio", c.MIMEType
+		content = append(content, c)
+	default:
+		return nil, nil, internalError("Unsupported sampling response content: %T", result.Content)
+	}
+
+	if conv != nil {
+		conv.messages = append(messages, &mcp.SamplingMessage{Role: result.Role, Content: result.Content})
+		conv.updatedAt = time.Now()
+		out.ConversationID = input.ConversationID
+		out.HistoryLength = len(conv.messages)
+	}
+
+	return &mcp.CallToolResult{Content: content}, out, nil
+}
+
+func (st *samplingTools) listConversationsHandler(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+	st.mu.Lock()
+	ids := make([]string, 0, len(st.conversations))
+	for id := range st.conversations {
+		ids = append(ids, id)
+	}
+	st.mu.Unlock()
+	sort.Strings(ids)
+
+	list := conversationList{Conversations: []conversationSummary{}}
+	var text strings.Builder
+	for _, id := range ids {
+		c := st.conversation(id, false)
+		if c == nil {
+			continue // Reset meanwhile
+		}
+		c.mu.Lock()
+		summary := conversationSummary{ConversationID: id, Messages: len(c.messages), UpdatedAt: c.updatedAt}
+		for _, m := range c.messages {
+			summary.EstimatedTokens += estimateTokens(m)
+		}
+		c.mu.Unlock()
+		if summary.Messages == 0 {
+			continue // First turn still in progress, or it failed
+		}
+		list.Conversations = append(list.Conversations, summary)
+		fmt.Fprintf(&text, "%s: %d messages, ~%d tokens\n", id, summary.Messages, summary.EstimatedTokens)
+	}
+	if len(list.Conversations) == 0 {
+		text.WriteString("No conversations.")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, list, nil
+}
+
+func (st *samplingTools) exportConversationHandler(_ context.Context, _ *mcp.CallToolRequest, input conversationInput) (*mcp.CallToolResult, any, error) {
+	c := st.conversation(input.ConversationID, false)
+	if c == nil {
+		return nil, nil, unknownConversation(input.ConversationID)
+	}
+	c.mu.Lock()
+	export := conversationExport{ConversationID: input.ConversationID, Messages: slices.Clone(c.messages)}
+	c.mu.Unlock()
+
+	var text strings.Builder
+	for _, m := range export.Messages {
+		fmt.Fprintf(&text, "%s: %s\n", m.Role, describeContent(m.Content))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, export, nil
+}
+
+func (st *samplingTools) resetConversationHandler(_ context.Context, _ *mcp.CallToolRequest, input conversationInput) (*mcp.CallToolResult, any, error) {
+	st.mu.Lock()
+	_, ok := st.conversations[input.ConversationID]
+	delete(st.conversations, input.ConversationID)
+	st.mu.Unlock()
+	if !ok {
+		return nil, nil, unknownConversation(input.ConversationID)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Conversation %q has been reset.", input.ConversationID)},
+		},
+	}, nil, nil
+}
+
+func unknownConversation(id string) *ToolError {
+	return notFound("Unknown conversation: %s", id)
+}
+
+// clientSupportsSampling reports whether the client declared the sampling
+// capability.
+func clientSupportsSampling(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Sampling != nil
+}
+
+// clientSupportsSamplingContext reports whether the client declared the
+// sampling.context capability.
+func clientSupportsSamplingContext(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	return params != nil && params.Capabilities != nil &&
+		params.Capabilities.Sampling != nil && params.Capabilities.Sampling.Context != nil
+}
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAskLLMTemperature(t *testing.T) {
+	var got *mcp.CreateMessageParams
+	cs := connect(t, NewServer(), &mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			got = req.Params
+			return &mcp.CreateMessageResult{Role: "assistant", Model: "test", Content: &mcp.TextContent{Text: "ok"}}, nil
+		},
+	})
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want func(float64) bool
+	}{
+		{"omitted", map[string]any{"prompt": "hi"}, func(t float64) bool { return t == 0 }},
+		{"set", map[string]any{"prompt": "hi", "temperature": 0.7}, func(t float64) bool { return t == 0.7 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "ask_llm", Arguments: tt.args})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.IsError {
+				t.Fatalf("ask_llm failed: %+v", res.Content)
+			}
+			if !tt.want(got.Temperature) {
+				t.Errorf("sampling request temperature = %v", got.Temperature)
+			}
+		})
+	}
+
+	// A zero temperature can't be sent, so it is rejected rather than
+	// silently becoming the model's default.
+	got = nil
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "ask_llm", Arguments: map[string]any{"prompt": "hi", "temperature": 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := toolError(t, res).Code; code != CodeInvalidArgument {
+		t.Errorf("temperature 0: code = %s, want %s", code, CodeInvalidArgument)
+	}
+	if got != nil {
+		t.Error("temperature 0 reached the client")
+	}
+}
+
+func TestConversationsEvictLeastRecentlyUsed(t *testing.T) {
+	st := newSamplingTools(nil)
+	for i := range maxConversations {
+		st.conversation(fmt.Sprint(i), true)
+	}
+	// Using the oldest makes conversation 1 the least recently used.
+	st.conversation("0", true)
+	st.conversation("new", true)
+
+	if len(st.conversations) != maxConversations {
+		t.Errorf("%d conversations kept, want %d", len(st.conversations), maxConversations)
+	}
+	for _, id := range []string{"0", "2", "new"} {
+		if st.conversation(id, false) == nil {
+			t.Errorf("conversation %s was forgotten", id)
+		}
+	}
+	if st.conversation("1", false) != nil {
+		t.Error("least recently used conversation 1 was kept")
+	}
+}
+
+func textMessage(role mcp.Role, text string) *mcp.SamplingMessage {
+	return &mcp.SamplingMessage{Role: role, Content: &mcp.TextContent{Text: text}}
+}
+
+// turns returns n turns of a user message and an assistant reply, numbered
+// from 0.
+func turns(n int) []*mcp.SamplingMessage {
+	var history []*mcp.SamplingMessage
+	for i := range n {
+		history = append(history, textMessage("user", fmt.Sprint("q", i)), textMessage("assistant", fmt.Sprint("a", i)))
+	}
+	return history
+}
+
+func TestTrimHistory(t *testing.T) {
+	prompt := []*mcp.SamplingMessage{textMessage("user", "now")}
+	big := strings.Repeat("x", 4*maxConversationTokens)
+	tests := []struct {
+		name    string
+		history []*mcp.SamplingMessage
+		prompt  []*mcp.SamplingMessage
+		want    []string // Texts of the result
+	}{
+		{
+			name:    "fits",
+			history: turns(2),
+			prompt:  prompt,
+			want:    []string{"q0", "a0", "q1", "a1", "now"},
+		},
+		{
+			// 20 messages of history and the prompt are one too many: the
+			// whole first turn goes, not just its question.
+			name:    "too many messages",
+			history: turns(maxConversationMessages / 2),
+			prompt:  prompt,
+			want:    append(texts(turns(maxConversationMessages / 2)[2:]), "now"),
+		},
+		{
+			// An attachment is a second user message in the same turn,
+			// and goes with it.
+			name: "turn with an attachment",
+			history: append([]*mcp.SamplingMessage{textMessage("user", "attachment")},
+				turns(maxConversationMessages/2)...),
+			prompt: prompt,
+			want:   append(texts(turns(maxConversationMessages / 2)[2:]), "now"),
+		},
+		{
+			name:    "too many tokens",
+			history: []*mcp.SamplingMessage{textMessage("user", big), textMessage("assistant", "a0"), textMessage("user", "q1"), textMessage("assistant", "a1")},
+			prompt:  prompt,
+			want:    []string{"q1", "a1", "now"},
+		},
+		{
+			name:    "prompt over the cap is kept",
+			history: turns(1),
+			prompt:  []*mcp.SamplingMessage{textMessage("user", "long"), textMessage("user", big)},
+			want:    []string{"long", big},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := texts(trimHistory(tt.history, tt.prompt)); !slices.Equal(got, tt.want) {
+				t.Errorf("trimHistory = %.60q, want %.60q", got, tt.want)
+			}
+		})
+	}
+}
+
+func texts(messages []*mcp.SamplingMessage) []string {
+	var texts []string
+	for _, m := range messages {
+		texts = append(texts, m.Content.(*mcp.TextContent).Text)
+	}
+	return texts
+}
+
+func TestAskLLMKeepsConversationHistory(t *testing.T) {
+	ctx := context.Background()
+	var sent []*mcp.SamplingMessage
+	cs := connect(t, NewServer(), &mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			sent = req.Params.Messages
+			prompt := sent[len(sent)-1].Content.(*mcp.TextContent).Text
+			return &mcp.CreateMessageResult{Role: "assistant", Model: "test", Content: &mcp.TextContent{Text: "re " + prompt}}, nil
+		},
+	})
+	ask := func(conversationID, prompt string) map[string]any {
+		t.Helper()
+		res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "ask_llm", Arguments: map[string]any{"prompt": prompt, "conversationId": conversationID}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.IsError {
+			t.Fatalf("ask_llm failed: %+v", res.Content)
+		}
+		return res.StructuredContent.(map[string]any)
+	}
+
+	ask("c", "p0")
+	out := ask("c", "p1")
+	if want := []string{"p0", "re p0", "p1"}; !slices.Equal(texts(sent), want) {
+		t.Errorf("second turn sent %q, want %q", texts(sent), want)
+	}
+	if out["historyLength"] != 4.0 {
+		t.Errorf("historyLength = %v, want 4", out["historyLength"])
+	}
+
+	// Another conversation starts empty.
+	ask("other", "q")
+	if want := []string{"q"}; !slices.Equal(texts(sent), want) {
+		t.Errorf("other conversation sent %q, want %q", texts(sent), want)
+	}
+
+	// Once the history is full, the oldest whole turn is dropped.
+	for i := 2; i <= maxConversationMessages/2; i++ {
+		ask("c", fmt.Sprint("p", i))
+	}
+	if len(sent) != maxConversationMessages-1 || texts(sent)[0] != "p1" || sent[0].Role != "user" {
+		t.Errorf("full conversation sent %q, want it to start at p1", texts(sent))
+	}
+}
+
+func TestAskLLMAttachments(t *testing.T) {
+	ctx := context.Background()
+	var sent []*mcp.SamplingMessage
+	cs := connect(t, NewServer(), &mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			sent = req.Params.Messages
+			return &mcp.CreateMessageResult{Role: "assistant", Model: "test", Content: &mcp.TextContent{Text: "ok"}}, nil
+		},
+	})
+	icon, err := cs.ReadResource(ctx, &mcp.ReadResourceParams{URI: "icon://robot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.StdEncoding.EncodeToString
+
+	tests := []struct {
+		name       string
+		attachment map[string]any
+		want       mcp.Content // Sent ahead of the prompt, if code is ""
+		code       ErrorCode
+	}{
+		{
+			name:       "inline image",
+			attachment: map[string]any{"type": "image", "data": b64([]byte("png")), "mimeType": "image/png"},
+			want:       &mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"},
+		},
+		{
+			name:       "inline audio",
+			attachment: map[string]any{"type": "audio", "data": b64([]byte("wav")), "mimeType": "audio/wav"},
+			want:       &mcp.AudioContent{Data: []byte("wav"), MIMEType: "audio/wav"},
+		},
+		{
+			name:       "resource",
+			attachment: map[string]any{"type": "image", "uri": "icon://robot"},
+			want:       &mcp.ImageContent{Data: icon.Contents[0].Blob, MIMEType: "image/png"},
+		},
+		{"data and uri", map[string]any{"type": "image", "data": b64([]byte("png")), "mimeType": "image/png", "uri": "icon://robot"}, nil, CodeInvalidArgument},
+		{"neither data nor uri", map[string]any{"type": "image"}, nil, CodeInvalidArgument},
+		{"bad base64", map[string]any{"type": "image", "data": "not base64!", "mimeType": "image/png"}, nil, CodeInvalidArgument},
+		{"no MIME type", map[string]any{"type": "image", "data": b64([]byte("png"))}, nil, CodeInvalidArgument},
+		{"MIME type of another kind", map[string]any{"type": "audio", "data": b64([]byte("png")), "mimeType": "image/png"}, nil, CodeInvalidArgument},
+		{"resource of another kind", map[string]any{"type": "audio", "uri": "icon://robot"}, nil, CodeInvalidArgument},
+		{"text resource", map[string]any{"type": "image", "uri": "about://server"}, nil, CodeInvalidArgument},
+		{"missing resource", map[string]any{"type": "image", "uri": "icon://nope"}, nil, CodeNotFound},
+		{"too large", map[string]any{"type": "image", "data": b64(make([]byte, maxAttachmentSize+1)), "mimeType": "image/png"}, nil, CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent = nil
+			res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "ask_llm", Arguments: map[string]any{
+				"prompt":      "What is this?",
+				"attachments": []any{tt.attachment},
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.code != "" {
+				if code := toolError(t, res).Code; code != tt.code {
+					t.Errorf("code = %s, want %s", code, tt.code)
+				}
+				if sent != nil {
+					t.Error("rejected attachment reached the client")
+				}
+				return
+			}
+			if res.IsError {
+				t.Fatalf("ask_llm failed: %+v", res.Content)
+			}
+			if len(sent) != 2 || sent[0].Role != "user" || !reflect.DeepEqual(sent[0].Content, tt.want) {
+				t.Errorf("sent %+v, want the attachment %+v then the prompt", sent, tt.want)
+			}
+		})
+	}
+}
+
+func TestAskLLMReturnsMediaReplies(t *testing.T) {
+	replies := map[string]mcp.Content{
+		"image": &mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"},
+		"audio": &mcp.AudioContent{Data: []byte("wav"), MIMEType: "audio/wav"},
+	}
+	cs := connect(t, NewServer(), &mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			prompt := req.Params.Messages[0].Content.(*mcp.TextContent).Text
+			return &mcp.CreateMessageResult{Role: "assistant", Model: "test", Content: replies[prompt]}, nil
+		},
+	})
+	for kind, reply := range replies {
+		t.Run(kind, func(t *testing.T) {
+			res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "ask_llm", Arguments: map[string]any{"prompt": kind}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.IsError {
+				t.Fatalf("ask_llm failed: %+v", res.Content)
+			}
+			if len(res.Content) != 2 || !reflect.DeepEqual(res.Content[1], reply) {
+				t.Errorf("content = %+v, want a description then %+v", res.Content, reply)
+			}
+			out := res.StructuredContent.(map[string]any)
+			if out["contentType"] != kind || out["text"] != "" && out["text"] != nil {
+				t.Errorf("structured output = %v, want contentType %s and no text", out, kind)
+			}
+		})
+	}
+}
@@ -10,34 +10,81 @@
 // during capability negotiation. See: https://modelcontextprotocol.io/
 package server
 
-import "github.com/modelcontextprotocol/go-sdk/mcp"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 
-// Global reference to server for dynamic tool loading.
-var globalServer *mcp.Server
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/elicit"
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/tasks"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
 
 // ServerInstructions provides guidance for AI assistants on how to use this server.
 const ServerInstructions = "# MCP Go Starter Server\n\n" +
 	"A demonstration MCP server showcasing Go SDK capabilities.\n\n" +
 	"## Recommended Workflows\n\n" +
 	"1. **Test connectivity** → Call `hello` to verify the server responds\n" +
-	"2. **Structured output** → Call `get_weather` to see typed response data\n" +
+	"2. **Structured output** → Call `get_weather` to see typed response data, or `chart_forecast` for a PNG chart of the forecast " +
+	"(`render_chart` draws any numeric series)\n" +
 	"3. **Progress reporting** → Call `long_task` to observe real-time progress notifications\n" +
-	"4. **Dynamic tools** → Call `load_bonus_tool`, then re-list tools to see `bonus_calculator` appear\n" +
-	"5. **LLM sampling** → Call `ask_llm` to have the server request a completion from the client\n" +
-	"6. **Elicitation** → Call `confirm_action` (form-based) or `get_feedback` (URL-based) to request user input\n\n" +
+	"4. **Background tasks** → Call `start_long_task` to get a task ID at once, then `get_task` or `get_task_result`\n" +
+	"5. **Dynamic tools** → Call `load_bonus_tool`, then re-list tools to see `bonus_calculator` appear; " +
+	"use `list_toolsets`, `enable_toolset` and `disable_toolset` to choose which groups of tools are listed\n" +
+	"6. **LLM sampling** → Call `ask_llm` to have the server request a completion from the client, " +
+	"or `run_agent` to let the client's LLM work through a task with this server's tools\n" +
+	"7. **Elicitation** → Call `confirm_action` (form-based) or `get_feedback` (URL-based) to request user input, " +
+	"or `plan_trip` for a multi-step form\n" +
+	"8. **Workspace roots** → Call `list_roots` to see which directories the client shares, " +
+	"then `list_directory`, `grep`, `stat` and `read_file` to explore files inside them\n\n" +
 	"## Multi-Tool Flows\n\n" +
 	"- **Full demo**: `hello` → `get_weather` → `long_task` → `load_bonus_tool` → `bonus_calculator`\n" +
+	"- **Resource links**: `list_items` links to each `item://{id}` resource; `get_item` embeds one. " +
+	"`get_weather` links to `weather://{city}`, which can be read again for fresh conditions\n" +
 	"- **Dynamic loading**: `load_bonus_tool` triggers a `tools/list_changed` notification — refresh your tool list to see `bonus_calculator`\n" +
 	"- **User interaction**: `confirm_action` demonstrates schema elicitation, `get_feedback` demonstrates URL elicitation\n\n" +
 	"## Notes\n\n" +
 	"- All tools include annotations (readOnlyHint, idempotentHint, openWorldHint) to guide safe usage\n" +
+	"- Destructive tools such as `reset_conversation` ask the user to confirm before running; an unconfirmed call returns an error and changes nothing\n" +
 	"- Resources and prompts are available for context and templating — use `resources/list` and `prompts/list` to discover them"
 
+// ConfigureFromEnv sets up new servers from the environment: the weather
+// provider (see WeatherProviderFromEnv), the toolsets enabled at start
+// (ToolsetsFromEnv), the confirmation policy (ConfirmPolicyFromEnv) and the
+// directories file tools may read (AllowedDirsFromEnv). If any setting is
+// invalid, it changes nothing and returns the error.
+func ConfigureFromEnv() error {
+	weatherProvider, err := WeatherProviderFromEnv()
+	if err != nil {
+		return err
+	}
+	toolsets, err := ToolsetsFromEnv()
+	if err != nil {
+		return err
+	}
+	confirmPolicy, err := ConfirmPolicyFromEnv()
+	if err != nil {
+		return err
+	}
+	allowedDirs, err := AllowedDirsFromEnv()
+	if err != nil {
+		return err
+	}
+	SetWeatherProvider(weatherProvider)
+	SetDefaultToolsets(toolsets)
+	SetConfirmPolicy(confirmPolicy)
+	SetAllowedDirs(allowedDirs)
+	return nil
+}
+
 // NewServer creates and configures the MCP server with all features.
 //
 // CAPABILITIES tell the client what this server supports. During the MCP
 // handshake, the client reads these to know which features are available.
 func NewServer() *mcp.Server {
+	workspace := newWorkspace()
 	server := mcp.NewServer(
 		&mcp.Implementation{
 			Name:    "mcp-go-starter",
@@ -45,34 +92,233 @@ func NewServer() *mcp.Server {
 		},
 		&mcp.ServerOptions{
 			Instructions: ServerInstructions,
+			// The client's roots are fetched when a session starts and
+			// again whenever the client says they changed.
+			InitializedHandler:      workspace.initialized,
+			RootsListChangedHandler: workspace.rootsChanged,
 			Capabilities: &mcp.ServerCapabilities{
 				Experimental: map[string]any{},
 				Resources: &mcp.ResourceCapabilities{
-					// ListChanged: false — our resources are static, so we never
-					// need to notify clients that the resource list has changed.
-					ListChanged: false,
+					// ListChanged: true — our own resources are static, but in
+					// gateway mode resources mirrored from upstream servers can
+					// come and go, and clients must be told to refresh.
+					ListChanged: true,
 					Subscribe:   false,
 				},
 				Tools: &mcp.ToolCapabilities{
-					// ListChanged: true — because load_bonus_tool adds tools
-					// dynamically at runtime. When a tool is added, the server
-					// sends a tools/list_changed notification so clients refresh.
+					// ListChanged: true — because toolsets (and load_bonus_tool)
+					// add and remove tools at runtime. Each change sends a
+					// tools/list_changed notification so clients refresh.
 					ListChanged: true,
 				},
 			},
 		},
 	)
 
-	registerTools(server)
+	workspace.server = server
+
+	// Added first, so they sit directly on the SDK's dispatch, beneath any
+	// middleware the commands add later. The gate goes under the loopback so
+	// that tools call each other through it too, and arguments are checked
+	// above the gate so that nobody is asked to confirm a call that would be
+	// rejected. toolErrors goes under them all, so that everything above sees
+	// the _meta it adds.
+	tools := &toolCache{}
+	gate := newConfirmGate(confirmPolicy, tools)
+	self := &loopback{}
+	server.AddReceivingMiddleware(self.middleware, validateArguments(tools), gate.middleware, toolErrors)
+	out := &outbound{}
+	server.AddSendingMiddleware(out.middleware, tools.middleware)
+
+	taskTools := &taskTools{manager: tasks.NewManager(nil)}
+	samplingTools := newSamplingTools(self)
+	elicitationTools := &elicitationTools{out: out}
+	newToolsets(server, map[string]func(*toolRegistry){
+		"demo":        registerDemoTools,
+		"weather":     registerWeatherTools,
+		"items":       registerItemTools,
+		"charts":      registerChartTools,
+		"tasks":       taskTools.registerTools,
+		"sampling":    samplingTools.registerTools,
+		"elicitation": elicitationTools.registerTools,
+		"workspace":   workspace.registerTools,
+		"calculator":  registerCalculatorTools,
+	})
+	taskTools.registerResources(server)
+	gate.registerResources(server)
+	workspace.registerResources(server)
 	registerResources(server)
 	registerPrompts(server)
 
 	return server
 }
 
-// SetGlobalServer sets the global server reference for dynamic tool loading.
-func SetGlobalServer(s *mcp.Server) {
-	globalServer = s
+// loopback lets tools make requests to their own server in-process, such as
+// reading one of its resources or calling another tool. Requests go through the same dispatch as the
+// client's, so they see exactly what the client would.
+type loopback struct {
+	handler mcp.MethodHandler
+}
+
+func (lb *loopback) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	lb.handler = next
+	return next
+}
+
+// readResource reads one of this server's resources on behalf of ss.
+func (lb *loopback) readResource(ctx context.Context, ss *mcp.ServerSession, uri string) (*mcp.ReadResourceResult, error) {
+	res, err := lb.handler(ctx, "resources/read", &mcp.ReadResourceRequest{
+		Session: ss,
+		Params:  &mcp.ReadResourceParams{URI: uri},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := res.(*mcp.ReadResourceResult)
+	if !ok {
+		return nil, fmt.Errorf("reading %s: unexpected result %T", uri, res)
+	}
+	return result, nil
+}
+
+// listTools lists the tools this server currently offers ss.
+func (lb *loopback) listTools(ctx context.Context, ss *mcp.ServerSession) ([]*mcp.Tool, error) {
+	var tools []*mcp.Tool
+	params := &mcp.ListToolsParams{}
+	for {
+		res, err := lb.handler(ctx, "tools/list", &mcp.ListToolsRequest{Session: ss, Params: params})
+		if err != nil {
+			return nil, err
+		}
+		result, ok := res.(*mcp.ListToolsResult)
+		if !ok {
+			return nil, fmt.Errorf("listing tools: unexpected result %T", res)
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			return tools, nil
+		}
+		params = &mcp.ListToolsParams{Cursor: result.NextCursor}
+	}
+}
+
+// toolCache remembers a server's tools, so that the middleware that looks
+// up each called tool doesn't list them all on every call. It is dropped
+// when the server announces that its tools changed, and refreshed when a
+// call names a tool it lacks: the SDK delays the announcement, and a new
+// tool must not be taken for an unknown one meanwhile.
+type toolCache struct {
+	mu    sync.Mutex
+	tools map[string]*mcp.Tool // By name; nil until listed
+}
+
+// middleware is sending middleware that drops the cache when the tool list
+// changes.
+func (c *toolCache) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "notifications/tools/list_changed" {
+			c.mu.Lock()
+			c.tools = nil
+			c.mu.Unlock()
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// lookup returns the named tool, or nil if the server has none, listing
+// the tools through lb on behalf of ss if the cache can't say.
+func (c *toolCache) lookup(ctx context.Context, lb *loopback, ss *mcp.ServerSession, name string) (*mcp.Tool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.tools[name]; ok {
+		return t, nil
+	}
+	listed, err := lb.listTools(ctx, ss)
+	if err != nil {
+		return nil, err
+	}
+	c.tools = make(map[string]*mcp.Tool, len(listed))
+	for _, t := range listed {
+		c.tools[t.Name] = t
+	}
+	return c.tools[name], nil
+}
+
+// callTool calls one of this server's tools on behalf of ss. Arguments are
+// validated against the tool's input schema, as for a client's call.
+func (lb *loopback) callTool(ctx context.Context, ss *mcp.ServerSession, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("calling %s: %w", name, err)
+	}
+	res, err := lb.handler(ctx, "tools/call", &mcp.CallToolRequest{
+		Session: ss,
+		Params:  &mcp.CallToolParamsRaw{Name: name, Arguments: raw},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := res.(*mcp.CallToolResult)
+	if !ok {
+		return nil, fmt.Errorf("calling %s: unexpected result %T", name, res)
+	}
+	return result, nil
+}
+
+// outbound lets tools send the client messages the SDK has no method for,
+// through the same sending dispatch as the SDK's own.
+type outbound struct {
+	handler mcp.MethodHandler
+}
+
+func (o *outbound) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	o.handler = next
+	return next
+}
+
+// notifyElicitationComplete tells ss that the URL-mode elicitation id has
+// been completed out of band.
+func (o *outbound) notifyElicitationComplete(ctx context.Context, ss *mcp.ServerSession, id string) error {
+	_, err := o.handler(ctx, "notifications/elicitation/complete", &mcp.ServerRequest[*mcp.ElicitationCompleteParams]{
+		Session: ss,
+		Params:  &mcp.ElicitationCompleteParams{ElicitationID: id},
+	})
+	return err
+}
+
+// formSession returns an elicit.Session that sends ss form elicitations
+// through o. Unlike ss.Elicit, whose check of the accepted content fails with
+// an error only its text identifies, it reports content that doesn't match
+// the requested schema as an *elicit.ValidationError, along with the result.
+func (o *outbound) formSession(ss *mcp.ServerSession) elicit.Session {
+	return formSession{o, ss}
+}
+
+type formSession struct {
+	out *outbound
+	ss  *mcp.ServerSession
+}
+
+func (f formSession) Elicit(ctx context.Context, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	if !clientSupportsElicitation(f.ss) {
+		return nil, errors.New("client does not support form elicitation")
+	}
+	p := *params
+	p.Mode = "form"
+	res, err := f.out.handler(ctx, "elicitation/create", &mcp.ServerRequest[*mcp.ElicitParams]{Session: f.ss, Params: &p})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := res.(*mcp.ElicitResult)
+	if !ok {
+		return nil, fmt.Errorf("elicitation: unexpected result %T", res)
+	}
+	if schema, ok := p.RequestedSchema.(map[string]any); ok && result.Action == "accept" {
+		if problems := elicit.Validate(schema, result.Content); len(problems) > 0 {
+			return result, &elicit.ValidationError{Problems: problems}
+		}
+	}
+	return result, nil
 }
 
 // extractParam extracts a parameter from a URI by removing the prefix.
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connect connects a client to s over in-memory transports.
+func connect(t *testing.T, s *mcp.Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := s.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, opts).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+// toolNames lists the names of the tools cs is offered.
+func toolNames(t *testing.T, cs *mcp.ClientSession) []string {
+	t.Helper()
+	var names []string
+	for tool, err := range cs.Tools(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+// connectAnswering connects a client to a new server that answers form
+// elicitations with answer. The SDK's client checks accepted content against
+// the schema before sending it, so answer is called above that check, as a
+// client in another language might be, and may return mismatched content.
+func connectAnswering(t *testing.T, answer func(*mcp.ElicitParams) *mcp.ElicitResult) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, &mcp.ClientOptions{
+		// Declares the elicitation capability; never called.
+		ElicitationHandler: func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return nil, errors.New("unreachable")
+		},
+	})
+	client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "elicitation/create" {
+				return next(ctx, method, req)
+			}
+			return answer(req.(*mcp.ElicitRequest).Params), nil
+		}
+	})
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := NewServer().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+// toolError returns the ToolError recorded in a failed result's _meta.
+func toolError(t *testing.T, res *mcp.CallToolResult) ToolError {
+	t.Helper()
+	if !res.IsError {
+		t.Fatalf("result isn't an error: %+v", res.Content)
+	}
+	raw, err := json.Marshal(res.Meta[ToolErrorMetaKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e ToolError
+	if err := json.Unmarshal(raw, &e); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestToolCache(t *testing.T) {
+	ctx := context.Background()
+	var lists int
+	tools := []*mcp.Tool{{Name: "a"}}
+	lb := &loopback{handler: func(_ context.Context, method string, _ mcp.Request) (mcp.Result, error) {
+		if method != "tools/list" {
+			t.Fatalf("unexpected %s", method)
+		}
+		lists++
+		return &mcp.ListToolsResult{Tools: tools}, nil
+	}}
+	c := &toolCache{}
+	lookup := func(name string) *mcp.Tool {
+		t.Helper()
+		tool, err := c.lookup(ctx, lb, nil, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tool
+	}
+
+	if lookup("a") == nil || lookup("a") == nil {
+		t.Fatal("tool a not found")
+	}
+	if lists != 1 {
+		t.Errorf("listed %d times for two lookups, want 1", lists)
+	}
+
+	// A tool added since the list was cached is found before the change is
+	// announced.
+	tools = append(tools, &mcp.Tool{Name: "b"})
+	if lookup("b") == nil {
+		t.Error("new tool b not found")
+	}
+	if lookup("missing") != nil {
+		t.Error("found a tool that doesn't exist")
+	}
+	if lists != 3 {
+		t.Errorf("listed %d times, want a list for each miss (3)", lists)
+	}
+
+	// A removed tool is forgotten once the change is announced.
+	tools = tools[1:]
+	notify := c.middleware(func(context.Context, string, mcp.Request) (mcp.Result, error) { return nil, nil })
+	if _, err := notify(ctx, "notifications/tools/list_changed", nil); err != nil {
+		t.Fatal(err)
+	}
+	if lookup("a") != nil {
+		t.Error("removed tool a still found after the list changed")
+	}
+}
+
+func TestNewToolIsValidatedAtOnce(t *testing.T) {
+	ctx := context.Background()
+	cs := connect(t, NewServer(), nil)
+	// Fill the cache before bonus_calculator exists.
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "hello", Arguments: map[string]any{"name": "Ada"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "load_bonus_tool"}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "bonus_calculator", Arguments: map[string]any{"expression": 42}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := toolError(t, res); e.Code != CodeInvalidArgument {
+		t.Errorf("error code = %s, want %s", e.Code, CodeInvalidArgument)
+	}
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	t.Cleanup(func() {
+		SetWeatherProvider(RandomWeatherProvider{})
+		SetDefaultToolsets(nil)
+		SetConfirmPolicy(ConfirmPolicy{})
+		SetAllowedDirs(nil)
+	})
+
+	t.Setenv("MCP_TOOLSETS", "demo,weather")
+	t.Setenv("MCP_CONFIRM_TOOLS", "hello")
+	if err := ConfigureFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(defaultToolsets, []string{"demo", "weather"}) || !slices.Equal(confirmPolicy.Tools, []string{"hello"}) {
+		t.Errorf("toolsets = %v, confirmed tools = %v", defaultToolsets, confirmPolicy.Tools)
+	}
+
+	// One bad setting changes nothing.
+	t.Setenv("MCP_TOOLSETS", "demo")
+	t.Setenv("MCP_ALLOWED_DIRS", "/does/not/exist")
+	if err := ConfigureFromEnv(); err == nil {
+		t.Error("no error for a missing allowed directory")
+	}
+	if !slices.Equal(defaultToolsets, []string{"demo", "weather"}) {
+		t.Errorf("toolsets = %v after a failed configuration, want them unchanged", defaultToolsets)
+	}
+}
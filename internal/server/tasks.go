@@ -0,0 +1,393 @@
+// tasks.go — Background task tools built on internal/tasks.
+//
+// These tools demonstrate the "start now, collect later" pattern:
+//   - start_long_task: Runs long_task in the background and returns a task ID at once
+//   - get_task:        Status and progress of a task
+//   - get_task_result: Waits for a task to finish and returns its tool result
+//   - list_tasks:      All tasks this server still remembers
+//   - cancel_task:     Stops a running task (its partial result is kept)
+//
+// Each task is also readable as a resource at task://{id}. Tasks belong to
+// the server that started them, so in HTTP mode every session has its own.
+// Tasks still running when the session that started them ends are
+// cancelled, so they don't go on working (and notifying) for nobody.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/tasks"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// relatedTaskMetaKey links a result to the task that produced it, as in the
+// MCP tasks utility.
+const relatedTaskMetaKey = "io.modelcontextprotocol/related-task"
+
+type taskIDInput struct {
+	TaskID string `json:"taskId" jsonschema:"ID returned by start_long_task"`
+}
+
+// taskList is list_tasks' structured output.
+type taskList struct {
+	Tasks []tasks.Task `json:"tasks"`
+}
+
+// taskSchema describes a tasks.Task, for OutputSchemas.
+var taskSchema = map[string]interface{}{
+	"type":  "object",
+	"title": "Task",
+	"properties": map[string]interface{}{
+		"taskId": map[string]interface{}{
+			"type":        "string",
+			"description": "Task ID",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Name of the task",
+		},
+		"status": map[string]interface{}{
+			"type":        "string",
+			"description": "Task status",
+			"enum":        []string{"working", "completed", "failed", "cancelled"},
+		},
+		"statusMessage": map[string]interface{}{
+			"type":        "string",
+			"description": "Latest progress or failure message",
+		},
+		"progress": map[string]interface{}{
+			"type":        "number",
+			"description": "Progress so far, out of total",
+		},
+		"total": map[string]interface{}{
+			"type":        "number",
+			"description": "Total amount of work, if known",
+		},
+		"createdAt": map[string]interface{}{
+			"type":        "string",
+			"format":      "date-time",
+			"description": "When the task was started",
+		},
+		"lastUpdatedAt": map[string]interface{}{
+			"type":        "string",
+			"format":      "date-time",
+			"description": "When the task last changed",
+		},
+		"ttl": map[string]interface{}{
+			"type":        "integer",
+			"description": "Milliseconds the task is retained after it finishes",
+		},
+	},
+	"required": []string{"taskId", "name", "status", "progress", "createdAt", "lastUpdatedAt", "ttl"},
+}
+
+var taskIDSchema = map[string]interface{}{
+	"type":  "object",
+	"title": "TaskIDInput",
+	"properties": map[string]interface{}{
+		"taskId": map[string]interface{}{
+			"type":        "string",
+			"title":       "Task ID",
+			"description": "ID returned by start_long_task",
+		},
+	},
+	"required": []string{"taskId"},
+}
+
+// taskTools holds the task manager shared by one server's task tools.
+type taskTools struct {
+	manager *tasks.Manager
+
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession][]string // IDs of the tasks each open session started
+}
+
+// track records that ss started the task with the given ID. The first time,
+// it starts waiting for ss to end, to cancel the tasks it started.
+func (t *taskTools) track(ss *mcp.ServerSession, id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessions == nil {
+		t.sessions = make(map[*mcp.ServerSession][]string)
+	}
+	ids, watching := t.sessions[ss]
+	t.sessions[ss] = append(ids, id)
+	if watching {
+		return
+	}
+	go func() {
+		_ = ss.Wait()
+		t.mu.Lock()
+		ids := t.sessions[ss]
+		delete(t.sessions, ss)
+		t.mu.Unlock()
+		for _, id := range ids {
+			_, _ = t.manager.Cancel(context.Background(), id) // ErrFinished for those already done
+		}
+	}()
+}
+
+// registerTools registers the "tasks" toolset.
+func (t *taskTools) registerTools(reg *toolRegistry) {
+	// start_long_task — Same work as long_task, but returns immediately. If
+	// the call carried a progress token, progress notifications keep using it
+	// until the task finishes.
+	addTool(reg, &mcp.Tool{
+		Name:         "start_long_task",
+		Description:  "Start long_task in the background and return a task ID immediately",
+		InputSchema:  longTaskInputSchema,
+		OutputSchema: taskSchema,
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    false, // Creates a task
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+		Icons: []mcp.Icon{
+			{
+				Source:   HOURGLASS_ICON,
+				MIMEType: "image/png",
+				Sizes:    []string{"256x256"},
+			},
+		},
+	}, t.startLongTaskHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:         "get_task",
+		Description:  "Get the status and progress of a background task",
+		InputSchema:  taskIDSchema,
+		OutputSchema: taskSchema,
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, t.getTaskHandler)
+
+	// get_task_result — Blocks until the task finishes, like tasks/result in
+	// the MCP tasks utility, and returns the task's own tool result.
+	addTool(reg, &mcp.Tool{
+		Name:        "get_task_result",
+		Description: "Wait for a background task to finish and return its result",
+		InputSchema: taskIDSchema,
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, t.getTaskResultHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:        "list_tasks",
+		Description: "List background tasks, oldest first",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"title":      "ListTasksInput",
+			"properties": map[string]interface{}{},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "TaskList",
+			"properties": map[string]interface{}{
+				"tasks": map[string]interface{}{
+					"type":  "array",
+					"items": taskSchema,
+				},
+			},
+			"required": []string{"tasks"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, t.listTasksHandler)
+
+	addTool(reg, &mcp.Tool{
+		Name:         "cancel_task",
+		Description:  "Cancel a running background task",
+		InputSchema:  taskIDSchema,
+		OutputSchema: taskSchema,
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    false,
+			DestructiveHint: boolPtr(false), // Partial results are kept
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, t.cancelTaskHandler)
+}
+
+// registerResources registers the task://{id} template. It stays available
+// while the tasks toolset is disabled, so earlier tasks can still be read.
+func (t *taskTools) registerResources(server *mcp.Server) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "Task Status",
+		Description: "Status of a background task by ID",
+		MIMEType:    "application/json",
+		URITemplate: "task://{id}",
+	}, t.taskResourceHandler)
+}
+
+func (t *taskTools) startLongTaskHandler(_ context.Context, req *mcp.CallToolRequest, input longTaskInput) (*mcp.CallToolResult, any, error) {
+	steps := input.Steps
+	if steps == 0 {
+		steps = 5
+	}
+	session := req.Session
+	progressToken := req.Params.GetProgressToken()
+
+	task := t.manager.Start("long_task: "+input.TaskName, func(ctx context.Context, report tasks.ProgressFunc) (*mcp.CallToolResult, error) {
+		// The original request has already returned, so notifications use
+		// the task's own ctx rather than the request's.
+		notify := func(progress float64, message string) {
+			if progressToken != nil {
+				_ = session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+					ProgressToken: progressToken,
+					Progress:      progress,
+					Total:         1.0,
+					Message:       message,
+				})
+			}
+		}
+		results, err := runLongTask(ctx, input.TaskName, steps, func(done, total int) {
+			message := fmt.Sprintf("Step %d/%d", done+1, total)
+			report(float64(done), float64(total), message)
+			notify(float64(done)/float64(total), message)
+		})
+		if err == nil {
+			notify(1.0, "Complete!")
+		}
+		res, out := longTaskToolResult(input.TaskName, steps, results, err)
+		res.StructuredContent = out
+		return res, nil
+	})
+	if session != nil {
+		t.track(session, task.ID)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Started task %s. Poll it with get_task or wait for it with get_task_result.", task.ID)},
+		},
+	}, task, nil
+}
+
+func (t *taskTools) getTaskHandler(_ context.Context, _ *mcp.CallToolRequest, input taskIDInput) (*mcp.CallToolResult, any, error) {
+	task, err := t.manager.Get(input.TaskID)
+	if err != nil {
+		return nil, nil, taskError(input.TaskID, err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: describeTask(task)},
+		},
+	}, task, nil
+}
+
+func (t *taskTools) getTaskResultHandler(ctx context.Context, _ *mcp.CallToolRequest, input taskIDInput) (*mcp.CallToolResult, any, error) {
+	task, stored, err := t.manager.Result(ctx, input.TaskID)
+	if err != nil {
+		return nil, nil, taskError(input.TaskID, err)
+	}
+
+	var res mcp.CallToolResult
+	if stored != nil {
+		res = *stored // Copy, so the stored result isn't shared between calls
+	} else {
+		res = *taskFailure(task).result()
+	}
+	// Keep the ToolError the result may already carry.
+	meta := maps.Clone(res.Meta)
+	if meta == nil {
+		meta = mcp.Meta{}
+	}
+	meta[relatedTaskMetaKey] = map[string]any{"taskId": task.ID}
+	res.Meta = meta
+	return &res, nil, nil
+}
+
+func (t *taskTools) listTasksHandler(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+	list := taskList{Tasks: t.manager.List()}
+
+	var text strings.Builder
+	if len(list.Tasks) == 0 {
+		text.WriteString("No tasks.")
+	}
+	for _, task := range list.Tasks {
+		text.WriteString(describeTask(task) + "\n")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, list, nil
+}
+
+func (t *taskTools) cancelTaskHandler(ctx context.Context, _ *mcp.CallToolRequest, input taskIDInput) (*mcp.CallToolResult, any, error) {
+	task, err := t.manager.Cancel(ctx, input.TaskID)
+	if err != nil {
+		return nil, nil, taskError(input.TaskID, err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: describeTask(task)},
+		},
+	}, task, nil
+}
+
+func (t *taskTools) taskResourceHandler(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	id := extractParam(req.Params.URI, "task://")
+
+	task, err := t.manager.Get(id)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+
+	jsonBytes, _ := json.MarshalIndent(task, "", "  ")
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      req.Params.URI,
+				MIMEType: "application/json",
+				Text:     string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// describeTask summarises a task in one line.
+func describeTask(task tasks.Task) string {
+	s := fmt.Sprintf("Task %s (%s): %s", task.ID, task.Name, task.Status)
+	if task.Total > 0 && !task.Status.Terminal() {
+		s += fmt.Sprintf(", %.0f/%.0f", task.Progress, task.Total)
+	}
+	if task.StatusMessage != "" {
+		s += " — " + task.StatusMessage
+	}
+	return s
+}
+
+// taskFailure reports a task that finished without a result.
+func taskFailure(task tasks.Task) *ToolError {
+	if task.Status == tasks.Cancelled {
+		return cancelled("%s", describeTask(task)).mayRetry()
+	}
+	return internalError("%s", describeTask(task))
+}
+
+// taskError reports a failed task lookup.
+func taskError(id string, err error) *ToolError {
+	if errors.Is(err, tasks.ErrNotFound) {
+		return notFound("Unknown task: %s", id)
+	}
+	if errors.Is(err, tasks.ErrFinished) {
+		return invalidArgument("Task %s already finished", id)
+	}
+	return internalError("Task %s: %w", id, err)
+}
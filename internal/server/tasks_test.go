@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/tasks"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTaskError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{tasks.ErrNotFound, CodeNotFound},
+		{tasks.ErrFinished, CodeInvalidArgument},
+		{context.Canceled, CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := taskError("abc", tt.err).Code; got != tt.code {
+			t.Errorf("taskError(%v) code = %s, want %s", tt.err, got, tt.code)
+		}
+	}
+}
+
+func TestTasksCancelledWhenSessionEnds(t *testing.T) {
+	shortSteps(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	tt := &taskTools{manager: tasks.NewManager(nil)}
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	tt.registerTools(&toolRegistry{server: s})
+	cs := connect(t, s, nil)
+
+	// 100 steps would take a second.
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "start_long_task", Arguments: map[string]any{"taskName": "t", "steps": 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := res.StructuredContent.(map[string]any)["taskId"].(string)
+	cs.Close()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	task, _, err := tt.manager.Result(waitCtx, id)
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("task still running after its session ended")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != tasks.Cancelled {
+		t.Errorf("task status = %s, want %s", task.Status, tasks.Cancelled)
+	}
+}
+
+func TestGetTaskResultReportsFailures(t *testing.T) {
+	shortSteps(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	tt := &taskTools{manager: tasks.NewManager(nil)}
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	tt.registerTools(&toolRegistry{server: s})
+	cs := connect(t, s, nil)
+
+	failed := tt.manager.Start("broken", func(context.Context, tasks.ProgressFunc) (*mcp.CallToolResult, error) {
+		return nil, errors.New("disk on fire")
+	})
+	held := tt.manager.Start("held", func(ctx context.Context, _ tasks.ProgressFunc) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	// 100 steps would take a second.
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "start_long_task", Arguments: map[string]any{"taskName": "t", "steps": 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := res.StructuredContent.(map[string]any)["taskId"].(string)
+	for _, id := range []string{held.ID, long} {
+		if _, err := tt.manager.Cancel(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		code      ErrorCode
+		retryable bool
+	}{
+		{"failed", failed.ID, CodeInternal, false},
+		{"cancelled", held.ID, CodeCancelled, true},
+		{"cancelled long_task", long, CodeCancelled, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "get_task_result", Arguments: map[string]any{"taskId": tc.id}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e := toolError(t, res); e.Code != tc.code || e.Retryable != tc.retryable {
+				t.Errorf("error = %+v, want code %s, retryable %v", e, tc.code, tc.retryable)
+			}
+			if related, _ := res.Meta[relatedTaskMetaKey].(map[string]any); related["taskId"] != tc.id {
+				t.Errorf("%s = %v, want task %s", relatedTaskMetaKey, res.Meta[relatedTaskMetaKey], tc.id)
+			}
+		})
+	}
+}
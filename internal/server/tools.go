@@ -2,35 +2,63 @@
 //
 // Each tool demonstrates a different MCP capability:
 //   - hello:          Basic connectivity test (simplest possible tool)
-//   - get_weather:    Structured output with OutputSchema
-//   - long_task:      Progress reporting via NotifyProgress
-//   - ask_llm:        Sampling — asking the client's LLM a question
-//   - load_bonus_tool: Dynamic tool registration at runtime
+//   - get_weather:    Structured output with OutputSchema (data from a WeatherProvider)
+//   - get_forecast:   Structured output with an array of typed objects
+//   - list_items:     Resource links to each item://{id} (see content.go)
+//   - get_item:       An item://{id} resource embedded in the result
+//   - long_task:      Progress reporting via NotifyProgress, stopping early on cancellation
+//   - bonus_calculator: Registered at runtime (see toolsets.go)
 //   - confirm_action: Schema elicitation — structured user input forms
 //   - get_feedback:   URL elicitation — opening a web page for the user
+//   - plan_trip:      Multi-step elicitation wizard (see trip.go)
+//
+// Tools are grouped into toolsets; each register*Tools function below
+// registers one toolset.
 package server
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math/rand"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/elicit"
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/expr"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // Weather represents weather data returned by the get_weather tool.
+// Temperatures from providers are unrounded; the tool rounds them once
+// they're in the requested unit.
 type Weather struct {
-	Location    string `json:"location"`
-	Temperature int    `json:"temperature"`
-	Unit        string `json:"unit"`
-	Conditions  string `json:"conditions"`
-	Humidity    int    `json:"humidity"`
+	Location    string  `json:"location"`
+	Temperature float64 `json:"temperature"`
+	Unit        string  `json:"unit"`
+	Conditions  string  `json:"conditions"`
+	Humidity    int     `json:"humidity"`
+}
+
+// Forecast represents a multi-day forecast returned by the get_forecast tool.
+type Forecast struct {
+	Location string        `json:"location"`
+	Unit     string        `json:"unit"`
+	Days     []ForecastDay `json:"days"`
 }
 
-// Track if bonus tool is loaded (used by the dynamic tool loading demo).
-var bonusToolLoaded = false
+// ForecastDay is one day of a Forecast.
+type ForecastDay struct {
+	Date                string  `json:"date"` // YYYY-MM-DD, local to the location
+	High                float64 `json:"high"`
+	Low                 float64 `json:"low"`
+	PrecipitationChance int     `json:"precipitationChance"` // percent
+	Conditions          string  `json:"conditions"`
+}
 
 // Tool input types — the Go SDK auto-generates JSON Schema from these structs.
 
@@ -40,11 +68,22 @@ type helloInput struct {
 
 type weatherInput struct {
 	City string `json:"city" jsonschema:"City name to get weather for"`
+	Unit string `json:"unit,omitempty" jsonschema:"enum=celsius,enum=fahrenheit"`
+}
+
+type forecastInput struct {
+	City string `json:"city" jsonschema:"City name to get the forecast for"`
+	Days int    `json:"days,omitempty" jsonschema:"Number of days to forecast"`
+	Unit string `json:"unit,omitempty" jsonschema:"enum=celsius,enum=fahrenheit"`
 }
 
-type askLLMInput struct {
-	Prompt    string `json:"prompt" jsonschema:"The question or prompt to send to the LLM"`
-	MaxTokens int    `json:"maxTokens,omitempty" jsonschema:"Maximum tokens in response"`
+type getItemInput struct {
+	ID string `json:"id" jsonschema:"ID of the item"`
+}
+
+// itemList is list_items' structured output.
+type itemList struct {
+	Items []ItemData `json:"items"`
 }
 
 type longTaskInput struct {
@@ -52,10 +91,27 @@ type longTaskInput struct {
 	Steps    int    `json:"steps,omitempty" jsonschema:"Number of steps to simulate"`
 }
 
+// longTaskResult is long_task's structured output. If the task is cancelled,
+// Results holds the output of the steps that finished.
+type longTaskResult struct {
+	TaskName       string   `json:"taskName"`
+	TotalSteps     int      `json:"totalSteps"`
+	CompletedSteps int      `json:"completedSteps"`
+	Cancelled      bool     `json:"cancelled"`
+	Results        []string `json:"results"`
+}
+
 type calculatorInput struct {
-	A         float64 `json:"a" jsonschema:"First number"`
-	B         float64 `json:"b" jsonschema:"Second number"`
-	Operation string  `json:"operation" jsonschema:"enum=add,enum=subtract,enum=multiply,enum=divide"`
+	Expression string             `json:"expression" jsonschema:"Arithmetic expression to evaluate"`
+	Variables  map[string]float64 `json:"variables,omitempty" jsonschema:"Values for names used in the expression"`
+}
+
+// calculatorResult is bonus_calculator's structured output.
+type calculatorResult struct {
+	Expression string             `json:"expression"`
+	Parsed     string             `json:"parsed"`
+	Variables  map[string]float64 `json:"variables,omitempty"`
+	Result     float64            `json:"result"`
 }
 
 type confirmActionInput struct {
@@ -63,6 +119,12 @@ type confirmActionInput struct {
 	Destructive bool   `json:"destructive,omitempty" jsonschema:"Whether the action is destructive"`
 }
 
+// confirmForm is the form confirm_action shows the user.
+type confirmForm struct {
+	Confirm bool   `json:"confirm" elicit:"title=Confirm;description=Confirm the action"`
+	Reason  string `json:"reason,omitempty" elicit:"title=Reason;description=Optional reason for your choice"`
+}
+
 type feedbackInput struct {
 	Question string `json:"question" jsonschema:"The question to ask the user"`
 }
@@ -90,9 +152,23 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-func registerTools(server *mcp.Server) {
+// truncate shortens s to at most n bytes, ending it with "..." if cut. It
+// cuts on a rune boundary, so it never splits a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n - len("...")
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+// registerDemoTools registers the "demo" toolset.
+func registerDemoTools(reg *toolRegistry) {
 	// hello — The simplest tool. Use it to verify client↔server connectivity.
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(reg, &mcp.Tool{
 		Name:        "hello",
 		Description: "Say hello to a person",
 		InputSchema: map[string]interface{}{
@@ -103,6 +179,9 @@ func registerTools(server *mcp.Server) {
 					"type":        "string",
 					"title":       "Name",
 					"description": "Name of the person to greet",
+					"minLength":   1,
+					"maxLength":   100,
+					"pattern":     `\S`, // Not just spaces
 				},
 			},
 			"required": []string{"name"},
@@ -122,10 +201,65 @@ func registerTools(server *mcp.Server) {
 		},
 	}, helloHandler)
 
+	// long_task — Demonstrates progress reporting. Sends incremental progress
+	// notifications so clients can display a progress bar or status updates.
+	// It also honours cancellation: if the client sends notifications/cancelled
+	// (or disconnects), the task stops between steps and reports what it
+	// finished instead of running to completion.
+	addTool(reg, &mcp.Tool{
+		Name:        "long_task",
+		Description: "Simulate a long-running task with progress updates; stops early if cancelled",
+		InputSchema: longTaskInputSchema,
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "LongTaskResult",
+			"properties": map[string]interface{}{
+				"taskName": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the task",
+				},
+				"totalSteps": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of steps requested",
+				},
+				"completedSteps": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of steps that finished",
+				},
+				"cancelled": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether the task was cancelled before finishing",
+				},
+				"results": map[string]interface{}{
+					"type":        "array",
+					"description": "Output of each completed step",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+			},
+			"required": []string{"taskName", "totalSteps", "completedSteps", "cancelled", "results"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+		Icons: []mcp.Icon{
+			{
+				Source:   HOURGLASS_ICON,
+				MIMEType: "image/png",
+				Sizes:    []string{"256x256"},
+			},
+		},
+	}, longTaskHandler)
+}
+
+// registerWeatherTools registers the "weather" toolset.
+func registerWeatherTools(reg *toolRegistry) {
 	// get_weather — Demonstrates structured output with an OutputSchema.
 	// When OutputSchema is set, the second return value from the handler is
 	// validated against it, giving clients type-safe structured data.
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(reg, &mcp.Tool{
 		Name:        "get_weather",
 		Description: "Get the current weather for a city",
 		InputSchema: map[string]interface{}{
@@ -137,6 +271,13 @@ func registerTools(server *mcp.Server) {
 					"title":       "City",
 					"description": "City name to get weather for",
 				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"title":       "Unit",
+					"description": "Temperature unit",
+					"enum":        []string{"celsius", "fahrenheit"},
+					"default":     "celsius",
+				},
 			},
 			"required": []string{"city"},
 		},
@@ -149,7 +290,7 @@ func registerTools(server *mcp.Server) {
 					"description": "Display name of location",
 				},
 				"temperature": map[string]interface{}{
-					"type":        "integer",
+					"type":        "number",
 					"description": "Temperature value",
 				},
 				"unit": map[string]interface{}{
@@ -170,7 +311,7 @@ func registerTools(server *mcp.Server) {
 		Annotations: &mcp.ToolAnnotations{
 			ReadOnlyHint:    true,
 			DestructiveHint: boolPtr(false),
-			OpenWorldHint:   boolPtr(false), // Not real external call
+			OpenWorldHint:   boolPtr(true), // May call a real weather API, depending on the provider
 		},
 		Icons: []mcp.Icon{
 			{
@@ -181,66 +322,145 @@ func registerTools(server *mcp.Server) {
 		},
 	}, weatherHandler)
 
-	// ask_llm — Demonstrates MCP sampling: the server asks the *client's* LLM
-	// a question. This inverts the usual flow — instead of the AI calling a tool,
-	// the tool calls the AI. Useful for sub-queries and chain-of-thought.
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "ask_llm",
-		Description: "Ask the connected LLM a question using sampling",
+	// get_forecast — A sibling of get_weather whose structured output holds an
+	// array of typed objects. The same data is rendered as readable text for
+	// clients (and models) that only look at content.
+	addTool(reg, &mcp.Tool{
+		Name:        "get_forecast",
+		Description: "Get a daily weather forecast for a city",
 		InputSchema: map[string]interface{}{
 			"type":  "object",
-			"title": "AskLLMInput",
+			"title": "ForecastInput",
 			"properties": map[string]interface{}{
-				"prompt": map[string]interface{}{
+				"city": map[string]interface{}{
 					"type":        "string",
-					"title":       "Prompt",
-					"description": "The question or prompt to send to the LLM",
+					"title":       "City",
+					"description": "City name to get the forecast for",
 				},
-				"maxTokens": map[string]interface{}{
+				"days": map[string]interface{}{
 					"type":        "integer",
-					"title":       "Max Tokens",
-					"description": "Maximum tokens in response",
-					"default":     100,
+					"title":       "Days",
+					"description": "Number of days to forecast, starting today",
+					"minimum":     1,
+					"maximum":     maxForecastDays,
+					"default":     defaultForecastDays,
+				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"title":       "Unit",
+					"description": "Temperature unit",
+					"enum":        []string{"celsius", "fahrenheit"},
+					"default":     "celsius",
+				},
+			},
+			"required": []string{"city"},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "Forecast",
+			"properties": map[string]interface{}{
+				"location": map[string]interface{}{
+					"type":        "string",
+					"description": "Display name of location",
+				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"description": "Temperature unit",
+				},
+				"days": map[string]interface{}{
+					"type":        "array",
+					"description": "One entry per day, starting today",
+					"items": map[string]interface{}{
+						"type":  "object",
+						"title": "ForecastDay",
+						"properties": map[string]interface{}{
+							"date": map[string]interface{}{
+								"type":        "string",
+								"format":      "date",
+								"description": "Date (YYYY-MM-DD)",
+							},
+							"high": map[string]interface{}{
+								"type":        "number",
+								"description": "Daily high temperature",
+							},
+							"low": map[string]interface{}{
+								"type":        "number",
+								"description": "Daily low temperature",
+							},
+							"precipitationChance": map[string]interface{}{
+								"type":        "integer",
+								"minimum":     0,
+								"maximum":     100,
+								"description": "Chance of precipitation (percent)",
+							},
+							"conditions": map[string]interface{}{
+								"type":        "string",
+								"description": "Weather conditions",
+							},
+						},
+						"required": []string{"date", "high", "low", "precipitationChance", "conditions"},
+					},
 				},
 			},
-			"required": []string{"prompt"},
+			"required": []string{"location", "unit", "days"},
 		},
 		Annotations: &mcp.ToolAnnotations{
 			ReadOnlyHint:    true,
 			DestructiveHint: boolPtr(false),
-			OpenWorldHint:   boolPtr(false),
+			OpenWorldHint:   boolPtr(true), // May call a real weather API, depending on the provider
 		},
 		Icons: []mcp.Icon{
 			{
-				Source:   ROBOT_ICON,
+				Source:   SUN_BEHIND_CLOUD_ICON,
 				MIMEType: "image/png",
 				Sizes:    []string{"256x256"},
 			},
 		},
-	}, askLLMHandler)
+	}, forecastHandler)
+}
 
-	// long_task — Demonstrates progress reporting. Sends incremental progress
-	// notifications so clients can display a progress bar or status updates.
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "long_task",
-		Description: "Simulate a long-running task with progress updates",
+var itemSchema = map[string]interface{}{
+	"type":  "object",
+	"title": "Item",
+	"properties": map[string]interface{}{
+		"id": map[string]interface{}{
+			"type":        "string",
+			"description": "Item ID",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Item name",
+		},
+		"description": map[string]interface{}{
+			"type":        "string",
+			"description": "What the item is",
+		},
+	},
+	"required": []string{"id", "name", "description"},
+}
+
+// registerItemTools registers the "items" toolset.
+func registerItemTools(reg *toolRegistry) {
+	// list_items — Returns a resource link per item instead of the items'
+	// data; the client reads the ones it wants.
+	addTool(reg, &mcp.Tool{
+		Name:        "list_items",
+		Description: "List the items in the example data store, with a link to each item's resource",
 		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"title":      "ListItemsInput",
+			"properties": map[string]interface{}{},
+		},
+		OutputSchema: map[string]interface{}{
 			"type":  "object",
-			"title": "LongTaskInput",
+			"title": "ItemList",
 			"properties": map[string]interface{}{
-				"taskName": map[string]interface{}{
-					"type":        "string",
-					"title":       "Task Name",
-					"description": "Name for this task",
-				},
-				"steps": map[string]interface{}{
-					"type":        "integer",
-					"title":       "Steps",
-					"description": "Number of steps to simulate",
-					"default":     5,
+				"items": map[string]interface{}{
+					"type":  "array",
+					"items": itemSchema,
 				},
 			},
-			"required": []string{"taskName"},
+			"required": []string{"items"},
 		},
 		Annotations: &mcp.ToolAnnotations{
 			ReadOnlyHint:    true,
@@ -248,40 +468,43 @@ func registerTools(server *mcp.Server) {
 			IdempotentHint:  true,
 			OpenWorldHint:   boolPtr(false),
 		},
-		Icons: []mcp.Icon{
-			{
-				Source:   HOURGLASS_ICON,
-				MIMEType: "image/png",
-				Sizes:    []string{"256x256"},
-			},
-		},
-	}, longTaskHandler)
+	}, listItemsHandler)
 
-	// load_bonus_tool — Demonstrates dynamic tool registration. Calling this
-	// adds "bonus_calculator" at runtime and notifies clients via tools/list_changed
-	// (enabled by ListChanged: true in server capabilities).
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "load_bonus_tool",
-		Description: "Dynamically register a new bonus tool",
+	// get_item — Embeds the item's resource, so the client has its contents
+	// without a separate resources/read.
+	addTool(reg, &mcp.Tool{
+		Name:        "get_item",
+		Description: "Get one item from the example data store, with its resource embedded",
 		InputSchema: map[string]interface{}{
-			"type":       "object",
-			"title":      "LoadBonusToolInput",
-			"properties": map[string]interface{}{},
+			"type":  "object",
+			"title": "GetItemInput",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "string",
+					"title":       "ID",
+					"description": "ID of the item",
+				},
+			},
+			"required": []string{"id"},
 		},
+		OutputSchema: itemSchema,
 		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
 			DestructiveHint: boolPtr(false),
-			IdempotentHint:  true, // Safe to call multiple times
+			IdempotentHint:  true,
 			OpenWorldHint:   boolPtr(false),
 		},
-		Icons: []mcp.Icon{
-			{
-				Source:   PACKAGE_ICON,
-				MIMEType: "image/png",
-				Sizes:    []string{"256x256"},
-			},
-		},
-	}, loadBonusToolHandler)
+	}, getItemHandler)
+}
+
+// elicitationTools holds what the "elicitation" toolset needs besides the
+// request's session: a way to tell the client a URL elicitation is done.
+type elicitationTools struct {
+	out *outbound
+}
 
+// registerTools registers the "elicitation" toolset.
+func (et *elicitationTools) registerTools(reg *toolRegistry) {
 	// =============================================================================
 	// Elicitation Tools - Request user input during tool execution
 	//
@@ -300,7 +523,7 @@ func registerTools(server *mcp.Server) {
 
 	// confirm_action — Schema elicitation: displays a structured form to the user.
 	// The client renders a dialog with typed fields based on the JSON schema.
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(reg, &mcp.Tool{
 		Name:        "confirm_action",
 		Description: "Request user confirmation before proceeding",
 		InputSchema: map[string]interface{}{
@@ -326,11 +549,11 @@ func registerTools(server *mcp.Server) {
 			DestructiveHint: boolPtr(false),
 			OpenWorldHint:   boolPtr(false),
 		},
-	}, confirmActionHandler)
+	}, et.confirmActionHandler)
 
 	// get_feedback — URL elicitation: opens a web page in the user's browser.
 	// Useful for OAuth flows, external forms, or documentation links.
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(reg, &mcp.Tool{
 		Name:        "get_feedback",
 		Description: "Request feedback from the user",
 		InputSchema: map[string]interface{}{
@@ -350,7 +573,48 @@ func registerTools(server *mcp.Server) {
 			DestructiveHint: boolPtr(false),
 			OpenWorldHint:   boolPtr(true), // Opens external URL
 		},
-	}, getFeedbackHandler)
+	}, et.getFeedbackHandler)
+
+	// plan_trip — A wizard: several elicitation steps, each validated and
+	// re-prompted until valid, with a typed result at the end.
+	addTool(reg, &mcp.Tool{
+		Name:        "plan_trip",
+		Description: "Plan a trip step by step with the user (destination, travelers, confirmation)",
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "PlanTripInput",
+			"properties": map[string]interface{}{
+				"city": map[string]interface{}{
+					"type":        "string",
+					"title":       "City",
+					"description": "Destination to suggest; the user can change it",
+				},
+			},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "TripPlan",
+			"properties": map[string]interface{}{
+				"city":      map[string]interface{}{"type": "string"},
+				"startDate": map[string]interface{}{"type": "string", "format": "date"},
+				"nights":    map[string]interface{}{"type": "integer"},
+				"adults":    map[string]interface{}{"type": "integer"},
+				"children":  map[string]interface{}{"type": "integer"},
+				"email":     map[string]interface{}{"type": "string", "format": "email"},
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Whether the user chose to book the trip",
+				},
+				"notes": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"city", "startDate", "nights", "adults", "children", "email", "confirm"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true, // Nothing is actually booked
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, et.planTripHandler)
 }
 
 func helloHandler(_ context.Context, _ *mcp.CallToolRequest, input helloInput) (*mcp.CallToolResult, any, error) {
@@ -361,65 +625,202 @@ func helloHandler(_ context.Context, _ *mcp.CallToolRequest, input helloInput) (
 	}, nil, nil
 }
 
-func weatherHandler(_ context.Context, _ *mcp.CallToolRequest, input weatherInput) (*mcp.CallToolResult, any, error) {
-	conditions := []string{"sunny", "cloudy", "rainy", "windy"}
-	weather := Weather{
-		Location:    input.City,
-		Temperature: 15 + rand.Intn(20),
-		Unit:        "celsius",
-		Conditions:  conditions[rand.Intn(len(conditions))],
-		Humidity:    40 + rand.Intn(40),
+// weatherHandler asks the configured WeatherProvider for current conditions
+// and converts the temperature to the requested unit.
+func weatherHandler(ctx context.Context, _ *mcp.CallToolRequest, input weatherInput) (*mcp.CallToolResult, any, error) {
+	unit := input.Unit
+	if unit == "" {
+		unit = "celsius"
+	}
+
+	weather, err := weatherProvider.CurrentWeather(ctx, input.City)
+	if err != nil {
+		return nil, nil, weatherError(input.City, err)
+	}
+
+	temperature, err := convertTemperature(weather.Temperature, unit)
+	if err != nil {
+		return nil, nil, weatherError(input.City, err)
 	}
+	weather.Temperature = temperature
+	weather.Unit = unit
 
 	jsonBytes, _ := json.MarshalIndent(weather, "", "  ")
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: string(jsonBytes)},
-		},
-	}, weather, nil
+	return toolResult(string(jsonBytes), currentWeatherLink(input.City)), weather, nil
+}
+
+// currentWeatherLink links to city's weather:// resource, which the client
+// can read again later for fresh conditions.
+func currentWeatherLink(city string) *mcp.ResourceLink {
+	return resourceLink(weatherURI(city), "Current weather in "+city,
+		"Current conditions, updated on every read", "application/json")
 }
 
-// askLLMHandler uses MCP sampling: req.Session.CreateMessage sends a prompt
-// to the client's LLM and returns its response.
-func askLLMHandler(ctx context.Context, req *mcp.CallToolRequest, input askLLMInput) (*mcp.CallToolResult, any, error) {
-	maxTokens := input.MaxTokens
-	if maxTokens == 0 {
-		maxTokens = 100
+const (
+	defaultForecastDays = 3
+	maxForecastDays     = 7
+)
+
+// forecastHandler returns a daily forecast both as structured content
+// (matching the OutputSchema) and as a plain-text table.
+func forecastHandler(ctx context.Context, _ *mcp.CallToolRequest, input forecastInput) (*mcp.CallToolResult, any, error) {
+	forecast, err := loadForecast(ctx, input)
+	if err != nil {
+		return nil, nil, err
 	}
+	return toolResult(forecastText(forecast), currentWeatherLink(input.City)), forecast, nil
+}
 
-	result, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
-		Messages: []*mcp.SamplingMessage{
-			{
-				Role:    "user",
-				Content: &mcp.TextContent{Text: input.Prompt},
-			},
-		},
-		MaxTokens: int64(maxTokens),
-	})
+// loadForecast fetches the forecast input asks for, in the requested unit.
+func loadForecast(ctx context.Context, input forecastInput) (*Forecast, error) {
+	unit := input.Unit
+	if unit == "" {
+		unit = "celsius"
+	}
+	days := input.Days
+	if days == 0 {
+		days = defaultForecastDays
+	}
+	if days < 1 || days > maxForecastDays {
+		return nil, invalidArgument("days must be between 1 and %d", maxForecastDays)
+	}
+
+	forecast, err := weatherProvider.Forecast(ctx, input.City, days)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Sampling not supported or failed: %v", err)},
-			},
-			IsError: true,
-		}, nil, nil
+		return nil, weatherError(input.City, err)
 	}
+	for i := range forecast.Days {
+		day := &forecast.Days[i]
+		if day.High, err = convertTemperature(day.High, unit); err != nil {
+			return nil, weatherError(input.City, err)
+		}
+		if day.Low, err = convertTemperature(day.Low, unit); err != nil {
+			return nil, weatherError(input.City, err)
+		}
+	}
+	forecast.Unit = unit
+	return forecast, nil
+}
 
-	text := "[non-text response]"
-	if tc, ok := result.Content.(*mcp.TextContent); ok {
-		text = tc.Text
+// forecastText renders a forecast as one line per day.
+func forecastText(forecast *Forecast) string {
+	symbol := unitSymbol(forecast.Unit)
+	var text strings.Builder
+	fmt.Fprintf(&text, "%d-day forecast for %s:\n", len(forecast.Days), forecast.Location)
+	for _, day := range forecast.Days {
+		fmt.Fprintf(&text, "%s: %s, high %.0f%s, low %.0f%s, %d%% chance of precipitation\n",
+			day.Date, day.Conditions, day.High, symbol, day.Low, symbol, day.PrecipitationChance)
 	}
+	return text.String()
+}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("LLM Response: %s", text)},
+func unitSymbol(unit string) string {
+	if unit == "fahrenheit" {
+		return "°F"
+	}
+	return "°C"
+}
+
+// weatherError reports a failed weather lookup.
+func weatherError(city string, err error) *ToolError {
+	if errors.Is(err, ErrUnknownCity) {
+		return notFound("Unknown city: %s", city)
+	}
+	if errors.Is(err, ErrUnsupportedUnit) || errors.Is(err, ErrTooManyDays) {
+		return invalidArgument("Weather lookup failed: %w", err)
+	}
+	var limited *RateLimitError
+	if errors.As(err, &limited) {
+		return rateLimited(limited.RetryAfter, "Weather lookup failed: %w", err)
+	}
+	// Most likely the weather service is unreachable or misbehaving.
+	return internalError("Weather lookup failed: %w", err).mayRetry()
+}
+
+func listItemsHandler(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+	list := itemList{Items: []ItemData{}}
+	for _, item := range itemsData {
+		list.Items = append(list.Items, item)
+	}
+	slices.SortFunc(list.Items, func(a, b ItemData) int { return strings.Compare(a.ID, b.ID) })
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%d item(s):\n", len(list.Items))
+	var links []mcp.Content
+	for _, item := range list.Items {
+		fmt.Fprintf(&text, "- %s: %s (%s)\n", item.ID, item.Name, itemURI(item.ID))
+		links = append(links, resourceLink(itemURI(item.ID), item.Name, item.Description, "application/json"))
+	}
+	return toolResult(text.String(), links...), list, nil
+}
+
+func getItemHandler(_ context.Context, _ *mcp.CallToolRequest, input getItemInput) (*mcp.CallToolResult, any, error) {
+	item, ok := itemsData[input.ID]
+	if !ok {
+		return nil, nil, notFound("No item with ID %q; list_items shows the available IDs", input.ID)
+	}
+	contents, err := itemContents(item)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := fmt.Sprintf("%s: %s", item.Name, item.Description)
+	return toolResult(text, embeddedResource(contents)), item, nil
+}
+
+// longTaskStepDuration is how long each simulated long_task step takes.
+var longTaskStepDuration = time.Second
+
+// maxLongTaskSteps caps long_task's steps, so that no call runs for longer
+// than a few minutes.
+const maxLongTaskSteps = 100
+
+// longTaskInputSchema is the input of long_task and start_long_task.
+var longTaskInputSchema = map[string]interface{}{
+	"type":  "object",
+	"title": "LongTaskInput",
+	"properties": map[string]interface{}{
+		"taskName": map[string]interface{}{
+			"type":        "string",
+			"title":       "Task Name",
+			"description": "Name for this task",
+			"minLength":   1,
+			"maxLength":   100,
 		},
-	}, nil, nil
+		"steps": map[string]interface{}{
+			"type":        "integer",
+			"title":       "Steps",
+			"description": "Number of steps to simulate",
+			"minimum":     1,
+			"maximum":     maxLongTaskSteps,
+			"default":     5,
+		},
+	},
+	"required": []string{"taskName"},
+}
+
+// runLongTask simulates steps units of work. It calls progress before each
+// step and stops as soon as ctx is done, returning the results of the steps
+// that completed along with ctx's error.
+func runLongTask(ctx context.Context, taskName string, steps int, progress func(done, total int)) ([]string, error) {
+	results := []string{}
+	for i := 0; i < steps; i++ {
+		progress(i, steps)
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		case <-time.After(longTaskStepDuration):
+		}
+		results = append(results, fmt.Sprintf("%s: step %d done", taskName, i+1))
+	}
+	return results, nil
 }
 
 // longTaskHandler sends progress notifications via req.Session.NotifyProgress.
 // The progressToken comes from the client's original request — if nil, the
 // client didn't request progress updates, so we skip notifications.
+//
+// ctx is cancelled when the client sends notifications/cancelled for this
+// request, so the handler returns promptly with whatever steps finished.
 func longTaskHandler(ctx context.Context, req *mcp.CallToolRequest, input longTaskInput) (*mcp.CallToolResult, any, error) {
 	steps := input.Steps
 	if steps == 0 {
@@ -427,19 +828,17 @@ func longTaskHandler(ctx context.Context, req *mcp.CallToolRequest, input longTa
 	}
 	progressToken := req.Params.GetProgressToken()
 
-	for i := 0; i < steps; i++ {
+	results, err := runLongTask(ctx, input.TaskName, steps, func(done, total int) {
 		if progressToken != nil {
 			_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
 				ProgressToken: progressToken,
-				Progress:      float64(i) / float64(steps),
+				Progress:      float64(done) / float64(total),
 				Total:         1.0,
-				Message:       fmt.Sprintf("Step %d/%d", i+1, steps),
+				Message:       fmt.Sprintf("Step %d/%d", done+1, total),
 			})
 		}
-		time.Sleep(1 * time.Second)
-	}
-
-	if progressToken != nil {
+	})
+	if err == nil && progressToken != nil {
 		_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
 			ProgressToken: progressToken,
 			Progress:      1.0,
@@ -448,100 +847,118 @@ func longTaskHandler(ctx context.Context, req *mcp.CallToolRequest, input longTa
 		})
 	}
 
+	res, out := longTaskToolResult(input.TaskName, steps, results, err)
+	return res, out, nil
+}
+
+// longTaskToolResult builds long_task's result from the output of
+// runLongTask. A non-nil err means the task was cancelled part-way.
+func longTaskToolResult(taskName string, steps int, results []string, err error) (*mcp.CallToolResult, longTaskResult) {
+	out := longTaskResult{
+		TaskName:       taskName,
+		TotalSteps:     steps,
+		CompletedSteps: len(results),
+		Cancelled:      err != nil,
+		Results:        results,
+	}
+	if err != nil {
+		return cancelled("Task %q cancelled after %d of %d steps", taskName, len(results), steps).mayRetry().result(), out
+	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Task %q completed successfully after %d steps!", input.TaskName, steps)},
+			&mcp.TextContent{Text: fmt.Sprintf("Task %q completed successfully after %d steps!", taskName, steps)},
 		},
-	}, nil, nil
+	}, out
 }
 
-func loadBonusToolHandler(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
-	if bonusToolLoaded {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Bonus tool is already loaded! Try calling 'bonus_calculator'."},
-			},
-		}, nil, nil
-	}
-
-	if globalServer != nil {
-		mcp.AddTool(globalServer, &mcp.Tool{
-			Name:        "bonus_calculator",
-			Description: "A calculator that was dynamically loaded",
-			InputSchema: map[string]interface{}{
-				"type":  "object",
-				"title": "CalculatorInput",
-				"properties": map[string]interface{}{
-					"a": map[string]interface{}{
-						"type":        "number",
-						"title":       "First Number",
-						"description": "First number",
-					},
-					"b": map[string]interface{}{
-						"type":        "number",
-						"title":       "Second Number",
-						"description": "Second number",
-					},
-					"operation": map[string]interface{}{
-						"type":        "string",
-						"title":       "Operation",
-						"description": "Operation to perform",
-						"enum":        []string{"add", "subtract", "multiply", "divide"},
-					},
+// registerCalculatorTools registers the "calculator" toolset, which is off
+// by default and loaded on demand by load_bonus_tool or enable_toolset.
+func registerCalculatorTools(reg *toolRegistry) {
+	description := "A calculator that was dynamically loaded. Evaluates arithmetic expressions " +
+		"with + - * / % ^, parentheses, the constants pi and e, named variables, and the functions " +
+		strings.Join(expr.Functions(), ", ") + "."
+	addTool(reg, &mcp.Tool{
+		Name:        "bonus_calculator",
+		Description: description,
+		InputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "CalculatorInput",
+			"properties": map[string]interface{}{
+				"expression": map[string]interface{}{
+					"type":        "string",
+					"title":       "Expression",
+					"description": "Arithmetic expression to evaluate, e.g. \"2 * (x + 1) ^ 2\" or \"sqrt(pow(3, 2) + 16)\"",
+					"minLength":   1,
+					"maxLength":   1000,
+				},
+				"variables": map[string]interface{}{
+					"type":                 "object",
+					"title":                "Variables",
+					"description":          "Values for names used in the expression",
+					"propertyNames":        map[string]interface{}{"pattern": "^[A-Za-z_][A-Za-z0-9_]*$"},
+					"additionalProperties": map[string]interface{}{"type": "number"},
 				},
-				"required": []string{"a", "b", "operation"},
-			},
-			Annotations: &mcp.ToolAnnotations{
-				ReadOnlyHint:    true, // Pure computation
-				DestructiveHint: boolPtr(false),
-				IdempotentHint:  true, // Same inputs = same outputs
-				OpenWorldHint:   boolPtr(false),
 			},
-			Icons: []mcp.Icon{
-				{
-					Source:   ABACUS_ICON,
-					MIMEType: "image/png",
-					Sizes:    []string{"256x256"},
+			"required": []string{"expression"},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "CalculatorResult",
+			"properties": map[string]interface{}{
+				"expression": map[string]interface{}{
+					"type":        "string",
+					"description": "The expression as given",
+				},
+				"parsed": map[string]interface{}{
+					"type":        "string",
+					"description": "The expression fully parenthesised, showing how it was parsed",
+				},
+				"variables": map[string]interface{}{
+					"type":                 "object",
+					"description":          "Variable values used",
+					"additionalProperties": map[string]interface{}{"type": "number"},
+				},
+				"result": map[string]interface{}{
+					"type":        "number",
+					"description": "Value of the expression",
 				},
 			},
-		}, calculatorHandler)
-	}
-	bonusToolLoaded = true
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: "Bonus tool 'bonus_calculator' has been loaded! The tools list has been updated."},
+			"required": []string{"expression", "parsed", "result"},
 		},
-	}, nil, nil
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true, // Pure computation
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true, // Same inputs = same outputs
+			OpenWorldHint:   boolPtr(false),
+		},
+		Icons: []mcp.Icon{
+			{
+				Source:   ABACUS_ICON,
+				MIMEType: "image/png",
+				Sizes:    []string{"256x256"},
+			},
+		},
+	}, calculatorHandler)
 }
 
+// calculatorHandler evaluates an arithmetic expression with internal/expr.
+// Malformed input is reported as a tool error pointing at the problem.
 func calculatorHandler(_ context.Context, _ *mcp.CallToolRequest, input calculatorInput) (*mcp.CallToolResult, any, error) {
-	var result float64
-	switch input.Operation {
-	case "add":
-		result = input.A + input.B
-	case "subtract":
-		result = input.A - input.B
-	case "multiply":
-		result = input.A * input.B
-	case "divide":
-		if input.B != 0 {
-			result = input.A / input.B
-		} else {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: "Error: division by zero"},
-				},
-				IsError: true,
-			}, nil, nil
-		}
+	parsed, result, err := expr.Evaluate(input.Expression, input.Variables)
+	if err != nil {
+		return nil, nil, invalidArgument("Error: %w", err)
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("%v %s %v = %v", input.A, input.Operation, input.B, result)},
+			&mcp.TextContent{Text: fmt.Sprintf("%s = %v", input.Expression, result)},
 		},
-	}, nil, nil
+	}, calculatorResult{
+		Expression: input.Expression,
+		Parsed:     parsed.String(),
+		Variables:  input.Variables,
+		Result:     result,
+	}, nil
 }
 
 // =============================================================================
@@ -555,98 +972,183 @@ func calculatorHandler(_ context.Context, _ *mcp.CallToolRequest, input calculat
 // Always handle all three cases gracefully.
 // =============================================================================
 
-func confirmActionHandler(ctx context.Context, req *mcp.CallToolRequest, input confirmActionInput) (*mcp.CallToolResult, any, error) {
-	// Form elicitation: Display a structured form with typed fields
-	// The client renders this as a dialog/form based on the JSON schema
-	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
-		Message: fmt.Sprintf("Please confirm: %s", input.Action),
-		RequestedSchema: map[string]any{
-			"type": "object",
-			"properties": map[string]any{
-				"confirm": map[string]any{
-					"type":        "boolean",
-					"title":       "Confirm",
-					"description": "Confirm the action",
-				},
-				"reason": map[string]any{
-					"type":        "string",
-					"title":       "Reason",
-					"description": "Optional reason for your choice",
-				},
+func (et *elicitationTools) confirmActionHandler(ctx context.Context, req *mcp.CallToolRequest, input confirmActionInput) (*mcp.CallToolResult, any, error) {
+	// Form elicitation: Display a structured form with typed fields.
+	// elicit.Ask derives the form's schema from confirmForm and decodes
+	// (and validates) the user's answer back into one.
+	form, err := elicit.Ask[confirmForm](ctx, et.out.formSession(req.Session), fmt.Sprintf("Please confirm: %s", input.Action))
+	var invalid *elicit.ValidationError
+	switch {
+	case errors.Is(err, elicit.ErrDeclined):
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("User declined to respond for: %s", input.Action)},
 			},
-			"required": []string{"confirm"},
-		},
-	})
-	if err != nil {
+		}, nil, nil
+	case errors.Is(err, elicit.ErrCancelled):
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Elicitation not supported or failed: %v", err)},
+				&mcp.TextContent{Text: fmt.Sprintf("User cancelled elicitation for: %s", input.Action)},
 			},
-			IsError: true,
 		}, nil, nil
+	case errors.As(err, &invalid):
+		// The answer won't get any better by asking the same way again.
+		return nil, nil, invalidArgument("The answer doesn't fit the form: %w", err)
+	case err != nil:
+		return nil, nil, elicitationFailure(req.Session, "form", err)
 	}
 
-	switch result.Action {
-	case "accept":
-		if confirmed, ok := result.Content["confirm"].(bool); ok && confirmed {
-			reason := "No reason provided"
-			if r, ok := result.Content["reason"].(string); ok && r != "" {
-				reason = r
-			}
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Action confirmed: %s\nReason: %s", input.Action, reason)},
-				},
-			}, nil, nil
-		}
+	if !form.Confirm {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: fmt.Sprintf("Action declined by user: %s", input.Action)},
 			},
 		}, nil, nil
+	}
+	reason := form.Reason
+	if reason == "" {
+		reason = "No reason provided"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Action confirmed: %s\nReason: %s", input.Action, reason)},
+		},
+	}, nil, nil
+}
+
+// feedbackTimeout is how long get_feedback waits for its hosted form.
+var feedbackTimeout = 10 * time.Minute
+
+// Where get_feedback hosts its form; nil means the GitHub issue form.
+var urlForms *elicit.URLForms
+
+// elicitationFailure reports a failed elicitation in mode ("form" or "url"),
+// telling clients that can't elicit that way apart from other failures.
+func elicitationFailure(ss *mcp.ServerSession, mode string, err error) *ToolError {
+	switch {
+	case mode == "form" && !clientSupportsElicitation(ss):
+		return unsupportedCapability("elicitation.form", "This client does not support form elicitation")
+	case mode == "url" && !clientSupportsURLElicitation(ss):
+		return unsupportedCapability("elicitation.url", "This client does not support URL elicitation")
+	}
+	return internalError("Elicitation failed: %w", err).mayRetry()
+}
+
+// clientSupportsURLElicitation reports whether the client can open URLs for
+// elicitation.
+func clientSupportsURLElicitation(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	return params != nil && params.Capabilities != nil &&
+		params.Capabilities.Elicitation != nil && params.Capabilities.Elicitation.URL != nil
+}
+
+// SetURLForms makes get_feedback host its own form with forms, which the
+// caller serves over HTTP (see cmd/http). nil restores the GitHub form.
+func SetURLForms(forms *elicit.URLForms) {
+	urlForms = forms
+}
+
+func (et *elicitationTools) getFeedbackHandler(ctx context.Context, req *mcp.CallToolRequest, input feedbackInput) (*mcp.CallToolResult, any, error) {
+	if urlForms == nil {
+		return et.githubFeedback(ctx, req, input)
+	}
+
+	// URL elicitation with a form this server hosts. The client only says
+	// whether the user agreed to open the page; the answer arrives when
+	// the form is submitted. However the call ends,
+	// notifications/elicitation/complete tells the client the elicitation
+	// is over.
+	form := urlForms.Open(elicit.Form{
+		Title:   "MCP Starters feedback",
+		Message: input.Question,
+		Fields: []elicit.FormField{
+			{Name: "rating", Label: "Rating (1-5)", Type: "select", Options: []string{"1", "2", "3", "4", "5"}, Required: true},
+			{Name: "comments", Label: "Comments", Type: "textarea"},
+		},
+	})
+	defer form.Close()
+
+	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Mode:          "url",
+		Message:       "Please provide feedback on MCP Starters by completing the form at the URL below:",
+		URL:           form.URL,
+		ElicitationID: form.ID,
+	})
+	if err != nil {
+		return nil, nil, elicitationFailure(req.Session, "url", err)
+	}
+	defer et.complete(ctx, req.Session, form.ID)
+
+	switch result.Action {
 	case "decline":
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("User declined to respond for: %s", input.Action)},
+				&mcp.TextContent{Text: "No problem! Feel free to provide feedback anytime."},
 			},
 		}, nil, nil
 	case "cancel":
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("User cancelled elicitation for: %s", input.Action)},
+				&mcp.TextContent{Text: "Feedback request cancelled."},
 			},
 		}, nil, nil
-	default:
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, feedbackTimeout)
+	defer cancel()
+	values, err := form.Wait(waitCtx)
+	switch {
+	case ctx.Err() != nil:
+		return nil, nil, ctx.Err() // The call was cancelled
+	case errors.Is(err, context.DeadlineExceeded):
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Unexpected elicitation response: %s", result.Action)},
+				&mcp.TextContent{Text: fmt.Sprintf("The feedback form was not submitted within %v.", feedbackTimeout)},
 			},
 		}, nil, nil
+	case err != nil:
+		return nil, nil, internalError("Waiting for feedback failed: %w", err)
+	}
+
+	comments := values["comments"]
+	if comments == "" {
+		comments = "(none)"
 	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Thank you for your feedback!\nRating: %s/5\nComments: %s", values["rating"], comments)},
+		},
+	}, nil, nil
+}
+
+// complete tells ss that the URL elicitation id is over, even if ctx is
+// done. A session that has gone can't be told, so errors are ignored.
+func (et *elicitationTools) complete(ctx context.Context, ss *mcp.ServerSession, id string) {
+	_ = et.out.notifyElicitationComplete(context.WithoutCancel(ctx), ss, id)
 }
 
-func getFeedbackHandler(ctx context.Context, req *mcp.CallToolRequest, input feedbackInput) (*mcp.CallToolResult, any, error) {
-	// URL elicitation: Open a web page in the user's browser
-	// Useful for OAuth flows, external forms, documentation links, etc.
-	feedbackURL := "https://github.com/SamMorrowDrums/mcp-starters/issues/new?template=workshop-feedback.yml"
+// githubFeedback sends the user to a GitHub issue form. Nothing comes back
+// from it, so accepting only means the user opened the page, and the
+// elicitation is complete as soon as the client answers.
+func (et *elicitationTools) githubFeedback(ctx context.Context, req *mcp.CallToolRequest, input feedbackInput) (*mcp.CallToolResult, any, error) {
+	query := url.Values{"template": {"workshop-feedback.yml"}}
 	if input.Question != "" {
-		feedbackURL += "&title=" + input.Question
+		query.Set("title", input.Question)
 	}
+	feedbackURL := "https://github.com/SamMorrowDrums/mcp-starters/issues/new?" + query.Encode()
 
 	// Request user to visit URL via URL elicitation
+	id := rand.Text()
 	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
-		Mode:    "url",
-		Message: "Please provide feedback on MCP Starters by completing the form at the URL below:",
-		URL:     feedbackURL,
+		Mode:          "url",
+		Message:       "Please provide feedback on MCP Starters by completing the form at the URL below:",
+		URL:           feedbackURL,
+		ElicitationID: id,
 	})
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("URL elicitation not supported or failed: %v", err)},
-			},
-			IsError: true,
-		}, nil, nil
+		return nil, nil, elicitationFailure(req.Session, "url", err)
 	}
+	et.complete(ctx, req.Session, id)
 
 	switch result.Action {
 	case "accept":
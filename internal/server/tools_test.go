@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/elicit"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// shortSteps makes long_task steps take d for the rest of the test.
+func shortSteps(t *testing.T, d time.Duration) {
+	old := longTaskStepDuration
+	longTaskStepDuration = d
+	t.Cleanup(func() { longTaskStepDuration = old })
+}
+
+func TestLongTaskCancelledMidRun(t *testing.T) {
+	shortSteps(t, 100*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Halfway through the second step.
+	time.AfterFunc(150*time.Millisecond, cancel)
+
+	start := time.Now()
+	res, out, err := longTaskHandler(ctx, &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{}}, longTaskInput{TaskName: "t", Steps: 50})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// All 50 steps would take 5s.
+	if elapsed > time.Second {
+		t.Errorf("handler took %v after cancellation, want it to return promptly", elapsed)
+	}
+	if e := toolError(t, res); e.Code != CodeCancelled || !e.Retryable {
+		t.Errorf("error = %+v, want a retryable %s", e, CodeCancelled)
+	}
+	result, ok := out.(longTaskResult)
+	if !ok {
+		t.Fatalf("structured output is %T, want longTaskResult", out)
+	}
+	want := longTaskResult{
+		TaskName:       "t",
+		TotalSteps:     50,
+		CompletedSteps: 1,
+		Cancelled:      true,
+		Results:        []string{"t: step 1 done"},
+	}
+	if result.TaskName != want.TaskName || result.TotalSteps != want.TotalSteps || result.CompletedSteps != want.CompletedSteps ||
+		result.Cancelled != want.Cancelled || !slices.Equal(result.Results, want.Results) {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+}
+
+func TestLongTaskCompletes(t *testing.T) {
+	shortSteps(t, time.Millisecond)
+	res, out, err := longTaskHandler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{}}, longTaskInput{TaskName: "t", Steps: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := out.(longTaskResult)
+	if res.IsError || result.Cancelled || result.CompletedSteps != 3 || len(result.Results) != 3 {
+		t.Errorf("result = %+v (error %v), want 3 completed steps", result, res.IsError)
+	}
+}
+
+func TestRunLongTaskStopsBetweenSteps(t *testing.T) {
+	shortSteps(t, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []int
+	results, err := runLongTask(ctx, "t", 10, func(done, total int) {
+		calls = append(calls, done)
+		if done == 2 {
+			cancel()
+		}
+	})
+	if err == nil {
+		t.Fatal("runLongTask returned no error after cancellation")
+	}
+	if len(results) != 2 {
+		t.Errorf("results = %q, want the 2 steps done before cancelling", results)
+	}
+	if !slices.Equal(calls, []int{0, 1, 2}) {
+		t.Errorf("progress calls = %v, want [0 1 2]", calls)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"much too long", 10, "much to..."},
+		// "é" is 2 bytes; cutting at 7 would split the fourth one.
+		{"éééééé", 10, "ééé..."},
+		{"日本語テキスト", 12, "日本語..."},
+		{"日本語テキスト", 11, "日本..."},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) || len(got) > tt.n {
+			t.Errorf("truncate(%q, %d) = %q: invalid UTF-8 or too long", tt.s, tt.n, got)
+		}
+	}
+}
+
+func TestConfirmAction(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    *mcp.ElicitResult
+		text      string    // Expected result text, if the call succeeds
+		code      ErrorCode // Expected error code, if it fails
+		retryable bool
+	}{
+		{
+			name:   "confirmed",
+			result: &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": true, "reason": "why not"}},
+			text:   "Action confirmed: deploy\nReason: why not",
+		},
+		{
+			name:   "unticked",
+			result: &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": false}},
+			text:   "Action declined by user: deploy",
+		},
+		{
+			name:   "declined",
+			result: &mcp.ElicitResult{Action: "decline"},
+			text:   "User declined to respond for: deploy",
+		},
+		{
+			name:   "mismatched content",
+			result: &mcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": "yes"}},
+			code:   CodeInvalidArgument,
+		},
+		{
+			name:   "missing field",
+			result: &mcp.ElicitResult{Action: "accept", Content: map[string]any{"reason": "forgot"}},
+			code:   CodeInvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := connectAnswering(t, func(*mcp.ElicitParams) *mcp.ElicitResult { return tt.result })
+			res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "confirm_action", Arguments: map[string]any{"action": "deploy"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.code != "" {
+				e := toolError(t, res)
+				if e.Code != tt.code || e.Retryable != tt.retryable {
+					t.Errorf("error = %s (retryable %v), want %s (retryable %v)", e.Code, e.Retryable, tt.code, tt.retryable)
+				}
+				return
+			}
+			if res.IsError {
+				t.Fatalf("confirm_action failed: %s", res.Content[0].(*mcp.TextContent).Text)
+			}
+			if text := res.Content[0].(*mcp.TextContent).Text; text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+		})
+	}
+}
+
+func TestGetFeedbackCompletesElicitation(t *testing.T) {
+	old := feedbackTimeout
+	feedbackTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { feedbackTimeout = old })
+
+	tests := []struct {
+		name   string
+		hosted bool   // Whether get_feedback hosts its own form
+		action string // The user's answer to the elicitation
+		submit bool   // Whether the user then submits the hosted form
+		text   string // Expected start of the result text
+	}{
+		{name: "submitted", hosted: true, action: "accept", submit: true, text: "Thank you for your feedback!\nRating: 4/5"},
+		{name: "declined", hosted: true, action: "decline", text: "No problem!"},
+		{name: "timed out", hosted: true, action: "accept", text: "The feedback form was not submitted within 50ms."},
+		{name: "github", action: "accept", text: "Thank you for providing feedback!"},
+		{name: "github declined", action: "decline", text: "No problem!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms := elicit.NewURLForms("http://localhost/elicit/")
+			if tt.hosted {
+				SetURLForms(forms)
+				t.Cleanup(func() { SetURLForms(nil) })
+			}
+
+			var asked string
+			completed := make(chan string, 1)
+			cs := connect(t, NewServer(), &mcp.ClientOptions{
+				Capabilities: &mcp.ClientCapabilities{
+					Elicitation: &mcp.ElicitationCapabilities{URL: &mcp.URLElicitationCapabilities{}},
+				},
+				ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+					asked = req.Params.ElicitationID
+					if tt.submit {
+						id := strings.TrimPrefix(req.Params.URL, "http://localhost/elicit")
+						post := httptest.NewRequest(http.MethodPost, id, strings.NewReader(url.Values{"rating": {"4"}}.Encode()))
+						post.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+						w := httptest.NewRecorder()
+						forms.ServeHTTP(w, post)
+						if w.Code != http.StatusOK {
+							t.Errorf("submitting the form: %d %s", w.Code, w.Body)
+						}
+					}
+					return &mcp.ElicitResult{Action: tt.action}, nil
+				},
+				ElicitationCompleteHandler: func(_ context.Context, req *mcp.ElicitationCompleteNotificationRequest) {
+					completed <- req.Params.ElicitationID
+				},
+			})
+
+			res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_feedback", Arguments: map[string]any{"question": "How was it?"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.IsError {
+				t.Fatalf("get_feedback failed: %s", res.Content[0].(*mcp.TextContent).Text)
+			}
+			if text := res.Content[0].(*mcp.TextContent).Text; !strings.HasPrefix(text, tt.text) {
+				t.Errorf("text = %q, want it to start with %q", text, tt.text)
+			}
+			select {
+			case id := <-completed:
+				if id != asked {
+					t.Errorf("completed elicitation %q, want %q", id, asked)
+				}
+			case <-time.After(time.Second):
+				t.Error("no notifications/elicitation/complete")
+			}
+		})
+	}
+}
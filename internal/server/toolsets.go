@@ -0,0 +1,481 @@
+// toolsets.go — Named groups of tools that can be enabled and disabled at runtime.
+//
+// WHY TOOLSETS?
+// Every tool a server lists costs the model context and attention. Grouping
+// tools into toolsets lets a client (or the model itself) start small and
+// switch on only what the task needs. Enabling a toolset registers its tools
+// and disabling removes them; either way the SDK sends
+// notifications/tools/list_changed so clients refresh their tool list.
+//
+// These catalog tools are always registered:
+//   - list_toolsets:   Lists every toolset, its tools and whether it is enabled
+//   - enable_toolset:  Registers a toolset's tools
+//   - disable_toolset: Removes a toolset's tools
+//   - load_bonus_tool: Shortcut for enabling the "calculator" toolset
+//
+// Toolsets belong to a server, so in HTTP mode each session has its own
+// selection. MCP_TOOLSETS chooses which start enabled (see ToolsetsFromEnv).
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Toolset describes a named group of tools.
+type Toolset struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tools       []string `json:"tools"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// toolsetCatalog lists every toolset. Here, Enabled means "enabled by default".
+// Tools is left empty: newToolsets records each toolset's tools as its
+// registration function adds them.
+var toolsetCatalog = []Toolset{
+	{
+		Name:        "demo",
+		Description: "Connectivity check and progress reporting",
+		Enabled:     true,
+	},
+	{
+		Name:        "weather",
+		Description: "Current weather and forecasts with structured output",
+		Enabled:     true,
+	},
+	{
+		Name:        "items",
+		Description: "Example data store whose results link to or embed item resources",
+		Enabled:     true,
+	},
+	{
+		Name:        "charts",
+		Description: "Line and bar charts returned as PNG images",
+		Enabled:     true,
+	},
+	{
+		Name:        "tasks",
+		Description: "Background tasks with status polling and cancellation",
+		Enabled:     true,
+	},
+	{
+		Name:        "sampling",
+		Description: "Tools that ask the client's LLM for completions",
+		Enabled:     true,
+	},
+	{
+		Name:        "elicitation",
+		Description: "Tools that ask the user for input",
+		Enabled:     true,
+	},
+	{
+		Name:        "workspace",
+		Description: "The client's workspace roots and read-only file access within them",
+		Enabled:     true,
+	},
+	{
+		Name:        "calculator",
+		Description: "Arithmetic expression evaluator",
+		Enabled:     false,
+	},
+}
+
+// Toolsets enabled when a server starts; nil means the catalog defaults.
+var defaultToolsets []string
+
+// SetDefaultToolsets sets which toolsets new servers start with. Names must
+// come from the catalog (ToolsetsFromEnv checks this); nil restores the
+// defaults.
+func SetDefaultToolsets(names []string) {
+	defaultToolsets = names
+}
+
+// ToolsetsFromEnv parses MCP_TOOLSETS: a comma-separated list of toolset
+// names, or "all". It returns nil when the variable is unset.
+func ToolsetsFromEnv() ([]string, error) {
+	value := strings.TrimSpace(os.Getenv("MCP_TOOLSETS"))
+	if value == "" {
+		return nil, nil
+	}
+	if value == "all" {
+		return AllToolsets(), nil
+	}
+	names := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if catalogToolset(name) == nil {
+			return nil, fmt.Errorf("MCP_TOOLSETS: unknown toolset %q (want %s or all)", name, strings.Join(toolsetNames(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func catalogToolset(name string) *Toolset {
+	for i := range toolsetCatalog {
+		if toolsetCatalog[i].Name == name {
+			return &toolsetCatalog[i]
+		}
+	}
+	return nil
+}
+
+// AllToolsets returns the name of every toolset in the catalog, for
+// SetDefaultToolsets.
+func AllToolsets() []string {
+	return toolsetNames()
+}
+
+func toolsetNames() []string {
+	var names []string
+	for _, t := range toolsetCatalog {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// toolsets tracks which toolsets are enabled on one server.
+type toolsets struct {
+	server   *mcp.Server
+	register map[string]func(*toolRegistry) // by toolset name
+
+	mu      sync.Mutex
+	enabled map[string]bool
+	tools   map[string][]string // by toolset name, as recorded by newToolsets
+}
+
+// toolRegistry is what a toolset's registration function adds its tools to.
+// It records their names, and registers them on server unless that is nil.
+type toolRegistry struct {
+	server *mcp.Server
+	names  []string
+}
+
+// addTool records t and adds it to reg's server, like mcp.AddTool.
+func addTool[In, Out any](reg *toolRegistry, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	reg.names = append(reg.names, t.Name)
+	if reg.server != nil {
+		mcp.AddTool(reg.server, t, h)
+	}
+}
+
+type toolsetInput struct {
+	Toolset string `json:"toolset" jsonschema:"Name of the toolset"`
+}
+
+// toolsetList is list_toolsets' structured output.
+type toolsetList struct {
+	Toolsets []Toolset `json:"toolsets"`
+}
+
+var toolsetSchema = map[string]interface{}{
+	"type":  "object",
+	"title": "Toolset",
+	"properties": map[string]interface{}{
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Toolset name",
+		},
+		"description": map[string]interface{}{
+			"type":        "string",
+			"description": "What the toolset is for",
+		},
+		"tools": map[string]interface{}{
+			"type":        "array",
+			"description": "Names of the tools in the toolset",
+			"items":       map[string]interface{}{"type": "string"},
+		},
+		"enabled": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Whether the toolset's tools are registered",
+		},
+	},
+	"required": []string{"name", "description", "tools", "enabled"},
+}
+
+// newToolsets registers the catalog tools on server and enables the default
+// toolsets. register maps each catalog toolset to its registration function.
+func newToolsets(server *mcp.Server, register map[string]func(*toolRegistry)) *toolsets {
+	ts := &toolsets{
+		server:   server,
+		register: register,
+		enabled:  make(map[string]bool),
+		tools:    make(map[string][]string),
+	}
+	for _, t := range toolsetCatalog {
+		// Registering on no server only records the names.
+		reg := &toolRegistry{}
+		register[t.Name](reg)
+		slices.Sort(reg.names)
+		ts.tools[t.Name] = reg.names
+		recordOwners(t.Name, reg.names)
+	}
+	ts.registerTools()
+
+	names := defaultToolsets
+	if names == nil {
+		for _, t := range toolsetCatalog {
+			if t.Enabled {
+				names = append(names, t.Name)
+			}
+		}
+	}
+	for _, name := range names {
+		_, _ = ts.enable(name)
+	}
+	return ts
+}
+
+// toolOwners maps each toolset tool to its toolset. Every server registers
+// the same tools, so newToolsets records them for all servers.
+var toolOwners struct {
+	sync.Mutex
+	toolset map[string]string
+}
+
+func recordOwners(toolset string, tools []string) {
+	toolOwners.Lock()
+	defer toolOwners.Unlock()
+	if toolOwners.toolset == nil {
+		toolOwners.toolset = make(map[string]string)
+	}
+	for _, tool := range tools {
+		toolOwners.toolset[tool] = toolset
+	}
+}
+
+// toolsetOf returns the name of the toolset that registers tool, or "" if
+// none does: the catalog tools, and tools mirrored in gateway mode.
+func toolsetOf(tool string) string {
+	toolOwners.Lock()
+	defer toolOwners.Unlock()
+	return toolOwners.toolset[tool]
+}
+
+// enable registers the named toolset's tools. It reports false if the
+// toolset was already enabled.
+func (ts *toolsets) enable(name string) (bool, error) {
+	register, ok := ts.register[name]
+	if !ok {
+		return false, notFound("unknown toolset %q", name)
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.enabled[name] {
+		return false, nil
+	}
+	register(&toolRegistry{server: ts.server})
+	ts.enabled[name] = true
+	return true, nil
+}
+
+// disable removes the named toolset's tools. It reports false if the
+// toolset was already disabled.
+func (ts *toolsets) disable(name string) (bool, error) {
+	if catalogToolset(name) == nil {
+		return false, notFound("unknown toolset %q", name)
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if !ts.enabled[name] {
+		return false, nil
+	}
+	ts.server.RemoveTools(ts.tools[name]...)
+	ts.enabled[name] = false
+	return true, nil
+}
+
+// list returns the catalog with this server's enabled state.
+func (ts *toolsets) list() []Toolset {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	list := make([]Toolset, len(toolsetCatalog))
+	for i, t := range toolsetCatalog {
+		t.Tools = slices.Clone(ts.tools[t.Name])
+		t.Enabled = ts.enabled[t.Name]
+		list[i] = t
+	}
+	return list
+}
+
+func (ts *toolsets) get(name string) Toolset {
+	for _, t := range ts.list() {
+		if t.Name == name {
+			return t
+		}
+	}
+	return Toolset{}
+}
+
+func (ts *toolsets) registerTools() {
+	toolsetInputSchema := map[string]interface{}{
+		"type":  "object",
+		"title": "ToolsetInput",
+		"properties": map[string]interface{}{
+			"toolset": map[string]interface{}{
+				"type":        "string",
+				"title":       "Toolset",
+				"description": "Name of the toolset (see list_toolsets)",
+				"enum":        toolsetNames(),
+			},
+		},
+		"required": []string{"toolset"},
+	}
+
+	mcp.AddTool(ts.server, &mcp.Tool{
+		Name:        "list_toolsets",
+		Description: "List the available toolsets, their tools and whether each is enabled",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"title":      "ListToolsetsInput",
+			"properties": map[string]interface{}{},
+		},
+		OutputSchema: map[string]interface{}{
+			"type":  "object",
+			"title": "ToolsetList",
+			"properties": map[string]interface{}{
+				"toolsets": map[string]interface{}{
+					"type":  "array",
+					"items": toolsetSchema,
+				},
+			},
+			"required": []string{"toolsets"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			ReadOnlyHint:    true,
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, ts.listToolsetsHandler)
+
+	mcp.AddTool(ts.server, &mcp.Tool{
+		Name:         "enable_toolset",
+		Description:  "Enable a toolset, adding its tools to the tool list",
+		InputSchema:  toolsetInputSchema,
+		OutputSchema: toolsetSchema,
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, ts.enableToolsetHandler)
+
+	mcp.AddTool(ts.server, &mcp.Tool{
+		Name:         "disable_toolset",
+		Description:  "Disable a toolset, removing its tools from the tool list",
+		InputSchema:  toolsetInputSchema,
+		OutputSchema: toolsetSchema,
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false), // Tools can be re-enabled at any time
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, ts.disableToolsetHandler)
+
+	// load_bonus_tool — Demonstrates dynamic tool registration. Calling this
+	// enables the "calculator" toolset, which adds "bonus_calculator" at
+	// runtime and notifies clients via tools/list_changed (enabled by
+	// ListChanged: true in server capabilities).
+	mcp.AddTool(ts.server, &mcp.Tool{
+		Name:        "load_bonus_tool",
+		Description: "Dynamically register a new bonus tool",
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"title":      "LoadBonusToolInput",
+			"properties": map[string]interface{}{},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true, // Safe to call multiple times
+			OpenWorldHint:   boolPtr(false),
+		},
+		Icons: []mcp.Icon{
+			{
+				Source:   PACKAGE_ICON,
+				MIMEType: "image/png",
+				Sizes:    []string{"256x256"},
+			},
+		},
+	}, ts.loadBonusToolHandler)
+}
+
+func (ts *toolsets) listToolsetsHandler(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+	list := toolsetList{Toolsets: ts.list()}
+
+	var text strings.Builder
+	for _, t := range list.Toolsets {
+		state := "disabled"
+		if t.Enabled {
+			state = "enabled"
+		}
+		fmt.Fprintf(&text, "%s (%s): %s — %s\n", t.Name, state, t.Description, strings.Join(t.Tools, ", "))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text.String()},
+		},
+	}, list, nil
+}
+
+func (ts *toolsets) enableToolsetHandler(_ context.Context, _ *mcp.CallToolRequest, input toolsetInput) (*mcp.CallToolResult, any, error) {
+	changed, err := ts.enable(input.Toolset)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := fmt.Sprintf("Toolset %q is already enabled.", input.Toolset)
+	if changed {
+		text = fmt.Sprintf("Enabled toolset %q. The tools list has been updated.", input.Toolset)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, ts.get(input.Toolset), nil
+}
+
+func (ts *toolsets) disableToolsetHandler(_ context.Context, _ *mcp.CallToolRequest, input toolsetInput) (*mcp.CallToolResult, any, error) {
+	changed, err := ts.disable(input.Toolset)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := fmt.Sprintf("Toolset %q is already disabled.", input.Toolset)
+	if changed {
+		text = fmt.Sprintf("Disabled toolset %q. The tools list has been updated.", input.Toolset)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, ts.get(input.Toolset), nil
+}
+
+func (ts *toolsets) loadBonusToolHandler(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+	changed, err := ts.enable("calculator")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !changed {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "Bonus tool is already loaded! Try calling 'bonus_calculator'."},
+			},
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "Bonus tool 'bonus_calculator' has been loaded! The tools list has been updated."},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func listToolsets(t *testing.T, cs *mcp.ClientSession) map[string]Toolset {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "list_toolsets"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list toolsetList
+	if err := json.Unmarshal(raw, &list); err != nil {
+		t.Fatal(err)
+	}
+	toolsets := make(map[string]Toolset)
+	for _, ts := range list.Toolsets {
+		toolsets[ts.Name] = ts
+	}
+	return toolsets
+}
+
+func TestToolsetsRecordTheirTools(t *testing.T) {
+	cs := connect(t, NewServer(), nil)
+	toolsets := listToolsets(t, cs)
+	if len(toolsets) != len(toolsetCatalog) {
+		t.Fatalf("list_toolsets returned %d toolsets, want %d", len(toolsets), len(toolsetCatalog))
+	}
+
+	// Each listed tool belongs to one toolset, or is a catalog tool.
+	owner := map[string]string{}
+	for name, ts := range toolsets {
+		if len(ts.Tools) == 0 {
+			t.Errorf("toolset %s has no tools", name)
+		}
+		for _, tool := range ts.Tools {
+			if other, ok := owner[tool]; ok {
+				t.Errorf("tool %s is in toolsets %s and %s", tool, other, name)
+			}
+			owner[tool] = name
+		}
+	}
+	for _, tool := range toolNames(t, cs) {
+		if _, ok := owner[tool]; !ok && !slices.Contains([]string{"list_toolsets", "enable_toolset", "disable_toolset", "load_bonus_tool"}, tool) {
+			t.Errorf("tool %s belongs to no toolset", tool)
+		}
+	}
+
+	// The calculator starts disabled, but its tools are still known.
+	if calc := toolsets["calculator"]; calc.Enabled || !slices.Equal(calc.Tools, []string{"bonus_calculator"}) {
+		t.Errorf("calculator = %+v, want disabled with bonus_calculator", calc)
+	}
+	if got := toolsetOf("bonus_calculator"); got != "calculator" {
+		t.Errorf("toolsetOf(bonus_calculator) = %q, want calculator", got)
+	}
+}
+
+func TestToolRegistry(t *testing.T) {
+	reg := &toolRegistry{}
+	registerWeatherTools(reg)
+	if !slices.Contains(reg.names, "get_weather") {
+		t.Errorf("recorded %v, want get_weather among them", reg.names)
+	}
+
+	s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	reg = &toolRegistry{server: s}
+	registerWeatherTools(reg)
+	if tools := toolNames(t, connect(t, s, nil)); !slices.Equal(tools, slices.Sorted(slices.Values(reg.names))) {
+		t.Errorf("server lists %v, recorded %v", tools, reg.names)
+	}
+}
+
+func TestDisableRemovesRecordedTools(t *testing.T) {
+	ctx := context.Background()
+	cs := connect(t, NewServer(), nil)
+	weather := listToolsets(t, cs)["weather"].Tools
+
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "disable_toolset", Arguments: map[string]any{"toolset": "weather"}}); err != nil {
+		t.Fatal(err)
+	}
+	tools := toolNames(t, cs)
+	for _, tool := range weather {
+		if slices.Contains(tools, tool) {
+			t.Errorf("%s still listed after disabling weather", tool)
+		}
+	}
+	if !slices.Contains(tools, "hello") {
+		t.Error("disabling weather removed hello")
+	}
+
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "enable_toolset", Arguments: map[string]any{"toolset": "weather"}}); err != nil {
+		t.Fatal(err)
+	}
+	tools = toolNames(t, cs)
+	for _, tool := range weather {
+		if !slices.Contains(tools, tool) {
+			t.Errorf("%s missing after re-enabling weather", tool)
+		}
+	}
+	if again := listToolsets(t, cs)["weather"].Tools; !slices.Equal(again, weather) {
+		t.Errorf("weather tools after re-enabling = %v, want %v", again, weather)
+	}
+}
@@ -0,0 +1,150 @@
+// trip.go — plan_trip, a multi-step elicitation wizard (see internal/elicit).
+//
+// The wizard asks for a destination, then the travelers, then a confirmation
+// that recaps both. Each step is checked against its schema and against
+// rules a schema can't express — the start date can't be in the past, the
+// weather provider must know the city, a booking covers at most 9 travelers —
+// and the user is asked again, with the reason, until the answer is valid.
+// Every step after the first can go back to the previous one.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/elicit"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxTripTravelers is the most travelers one booking covers. The form limits
+// below can't refer to it, so keep them in step.
+const maxTripTravelers = 9
+
+type tripInput struct {
+	City string `json:"city,omitempty" jsonschema:"Destination to suggest"`
+}
+
+// The wizard's forms, one per step. elicit.SchemaFor turns each into a
+// flat elicitation schema.
+type (
+	tripDestination struct {
+		City      string `json:"city" elicit:"title=City;description=Where are you going?;minLength=1"`
+		StartDate string `json:"startDate" elicit:"title=Start date;description=First night of the trip;format=date"`
+		Nights    int    `json:"nights" elicit:"title=Nights;min=1;max=14;default=3"`
+	}
+	tripTravelers struct {
+		Adults   int    `json:"adults" elicit:"title=Adults;min=1;max=9;default=1"`
+		Children int    `json:"children" elicit:"title=Children;optional;min=0;max=8;default=0"`
+		Email    string `json:"email" elicit:"title=Contact email;description=Where to send the itinerary;format=email"`
+	}
+	tripConfirmation struct {
+		Confirmed bool   `json:"confirm" elicit:"title=Book this trip"`
+		Notes     string `json:"notes,omitempty" elicit:"title=Notes;maxLength=200"`
+	}
+)
+
+// tripPlan is the wizard's typed result and plan_trip's structured output.
+type tripPlan struct {
+	tripDestination
+	tripTravelers
+	tripConfirmation
+}
+
+// tripWizard builds plan_trip's steps. city, if set, is suggested as the
+// destination.
+func tripWizard(ctx context.Context, city string) *elicit.Wizard {
+	destinationSchema := elicit.MustSchemaFor[tripDestination]()
+	if city != "" {
+		destinationSchema["properties"].(map[string]any)["city"].(map[string]any)["default"] = city
+	}
+
+	return &elicit.Wizard{
+		Title: "Plan a trip",
+		Steps: []elicit.Step{
+			{
+				Name:    "destination",
+				Message: "Where and when are you travelling?",
+				Schema:  destinationSchema,
+				Check: func(values map[string]any, _ elicit.Answers) error {
+					d, err := elicit.Decode[tripDestination](values)
+					if err != nil {
+						return err
+					}
+					if d.StartDate < time.Now().Format(time.DateOnly) {
+						return errors.New("start date can't be in the past")
+					}
+					// Only an unknown city is the user's problem; if the
+					// provider is down, let the trip through.
+					if _, err := weatherProvider.CurrentWeather(ctx, d.City); errors.Is(err, ErrUnknownCity) {
+						return fmt.Errorf("city %q isn't one we know; try a nearby larger city", d.City)
+					}
+					return nil
+				},
+			},
+			{
+				Name:    "travelers",
+				Message: "Who is travelling?",
+				Schema:  elicit.MustSchemaFor[tripTravelers](),
+				Check: func(values map[string]any, _ elicit.Answers) error {
+					t, err := elicit.Decode[tripTravelers](values)
+					if err != nil {
+						return err
+					}
+					if t.Adults+t.Children > maxTripTravelers {
+						return fmt.Errorf("a booking covers at most %d travelers", maxTripTravelers)
+					}
+					return nil
+				},
+			},
+			{
+				Name:    "confirm",
+				Message: "Please check your trip.",
+				Summary: func(answers elicit.Answers) string {
+					d, _ := elicit.Decode[tripDestination](answers["destination"])
+					t, _ := elicit.Decode[tripTravelers](answers["travelers"])
+					return fmt.Sprintf("%s from %s for %d nights\n%d adults, %d children\nItinerary to %s",
+						d.City, d.StartDate, d.Nights, t.Adults, t.Children, t.Email)
+				},
+				Schema: elicit.MustSchemaFor[tripConfirmation](),
+			},
+		},
+	}
+}
+
+// planTripHandler runs the wizard and reports the plan it produced. Nothing
+// is actually booked.
+func (et *elicitationTools) planTripHandler(ctx context.Context, req *mcp.CallToolRequest, input tripInput) (*mcp.CallToolResult, any, error) {
+	plan, err := elicit.Run[tripPlan](ctx, et.out.formSession(req.Session), tripWizard(ctx, input.City))
+	switch {
+	case errors.Is(err, elicit.ErrDeclined):
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "User declined to plan a trip."},
+			},
+		}, nil, nil
+	case errors.Is(err, elicit.ErrCancelled):
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "User cancelled trip planning."},
+			},
+		}, nil, nil
+	case errors.Is(err, elicit.ErrTooManyAttempts):
+		// The user may get the answers right another time.
+		return nil, nil, internalError("Trip planning stopped: %w", err).mayRetry()
+	case err != nil:
+		return nil, nil, elicitationFailure(req.Session, "form", err)
+	}
+
+	status := "Trip planned"
+	if !plan.Confirmed {
+		status = "Trip not booked"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("%s: %s from %s, %d nights, %d adults and %d children. Itinerary to %s.",
+				status, plan.City, plan.StartDate, plan.Nights, plan.Adults, plan.Children, plan.Email)},
+		},
+	}, plan, nil
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPlanTripRepromptsOnMismatchedContent(t *testing.T) {
+	replies := []map[string]any{
+		// nights doesn't match the schema's type.
+		{"city": "Paris", "startDate": "2099-06-01", "nights": "three"},
+		{"city": "Paris", "startDate": "2099-06-01", "nights": 3},
+		{"adults": 2, "children": 0, "email": "ada@example.com"},
+		{"confirm": true},
+	}
+	var messages []string
+	cs := connectAnswering(t, func(params *mcp.ElicitParams) *mcp.ElicitResult {
+		messages = append(messages, params.Message)
+		content := replies[0]
+		replies = replies[1:]
+		return &mcp.ElicitResult{Action: "accept", Content: content}
+	})
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "plan_trip"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("plan_trip failed: %s", res.Content[0].(*mcp.TextContent).Text)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("asked %d times, want 4", len(messages))
+	}
+	if !strings.Contains(messages[1], "Please fix: Nights must be a number") {
+		t.Errorf("second prompt = %q, want it to explain the mismatch", messages[1])
+	}
+}
@@ -0,0 +1,77 @@
+// validation.go — Checking tool arguments before handlers run.
+//
+// WHY CHECK HERE?
+// Each tool declares the limits on its arguments in its input schema: ranges
+// (minimum, maximum), lengths (minLength, maxItems...), patterns and enums.
+// The SDK checks arguments against the schema too, but stops at the first
+// problem and reports it as a protocol error, which many clients show the
+// user rather than the model. Here every problem is reported at once, as an
+// invalid_argument tool error the model can act on:
+//
+//	long_task: steps must be at most 100; taskName must not be empty
+//
+// The error's details list each offending field under "violations". This
+// applies to every tool, including those mirrored in gateway mode and those
+// run_agent calls; handlers can rely on their schema's limits holding.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/validate"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// validateArguments returns receiving middleware that rejects tools/call
+// requests whose arguments break the tool's input schema. It finds the
+// tool in tools.
+func validateArguments(tools *toolCache) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		self := &loopback{handler: next}
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if method != "tools/call" || !ok || call.Session == nil {
+				return next(ctx, method, req)
+			}
+			// Unknown tools, and arguments that aren't JSON, go straight
+			// through for the SDK to reject.
+			tool, err := tools.lookup(ctx, self, call.Session, call.Params.Name)
+			if err != nil {
+				return nil, err
+			}
+			if tool == nil || tool.InputSchema == nil {
+				return next(ctx, method, req)
+			}
+			schema, err := validate.Schema(tool.InputSchema)
+			if err != nil {
+				return next(ctx, method, req)
+			}
+			args := map[string]any{} // Missing arguments are an empty object
+			if raw := call.Params.Arguments; len(raw) > 0 && string(raw) != "null" {
+				var v any
+				if err := json.Unmarshal(raw, &v); err != nil {
+					return next(ctx, method, req)
+				}
+				args, _ = v.(map[string]any)
+				if args == nil {
+					return argumentsError(call.Params.Name, []validate.Violation{{Message: "arguments must be an object"}}).result(), nil
+				}
+			}
+			if violations := validate.Check(schema, args); len(violations) > 0 {
+				return argumentsError(call.Params.Name, violations).result(), nil
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// argumentsError reports a call to tool whose arguments break its schema.
+func argumentsError(tool string, violations []validate.Violation) *ToolError {
+	problems := make([]string, len(violations))
+	for i, v := range violations {
+		problems[i] = v.String()
+	}
+	return invalidArgument("%s: %s", tool, strings.Join(problems, "; ")).withDetail("violations", violations)
+}
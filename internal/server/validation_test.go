@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		tool   string
+		args   any
+		fields []string // The fields of the expected violations, in order
+	}{
+		{tool: "long_task", args: map[string]any{"taskName": "", "steps": 1000000}, fields: []string{"steps", "taskName"}},
+		{tool: "long_task", args: map[string]any{"taskName": "t", "steps": 2.5}, fields: []string{"steps"}},
+		{tool: "long_task", args: map[string]any{}, fields: []string{"taskName"}},
+		{tool: "hello", args: map[string]any{"name": ""}, fields: []string{"name", "name"}}, // Empty, and not matching \S
+		{tool: "hello", args: []any{"Ada"}, fields: []string{""}},
+	}
+	cs := connect(t, NewServer(), nil)
+	for _, tt := range tests {
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: tt.tool, Arguments: tt.args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := toolError(t, res)
+		if e.Code != CodeInvalidArgument {
+			t.Errorf("%s(%v): error code = %s, want %s", tt.tool, tt.args, e.Code, CodeInvalidArgument)
+			continue
+		}
+		var fields []string
+		violations, _ := e.Details["violations"].([]any)
+		for _, v := range violations {
+			fields = append(fields, v.(map[string]any)["field"].(string))
+		}
+		if !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("%s(%v): violations = %v, want fields %v", tt.tool, tt.args, violations, tt.fields)
+		}
+	}
+}
@@ -0,0 +1,410 @@
+// weather.go — Weather data providers for the get_weather and get_forecast tools.
+//
+// The weather tools don't know where their data comes from: they ask a
+// WeatherProvider. Three are included:
+//   - RandomWeatherProvider:    Made-up data, no setup required (the default)
+//   - FixtureWeatherProvider:   Fixed data loaded from a JSON file, for demos and tests
+//   - OpenMeteoWeatherProvider: Real data from the free Open-Meteo API (no key needed)
+//
+// Select one with the WEATHER_PROVIDER environment variable (see
+// WeatherProviderFromEnv). Providers always report Celsius, unrounded; the
+// tools convert to the unit the caller asked for and round once.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WeatherProvider looks up current conditions and daily forecasts for a
+// city. Implementations return temperatures in Celsius, an error wrapping
+// ErrUnknownCity when the city cannot be found, and one wrapping
+// ErrTooManyDays when they can't forecast as far ahead as asked.
+type WeatherProvider interface {
+	CurrentWeather(ctx context.Context, city string) (*Weather, error)
+	// Forecast returns the next days days, starting today.
+	Forecast(ctx context.Context, city string, days int) (*Forecast, error)
+}
+
+// ErrUnknownCity is returned (wrapped) by providers for cities they don't know.
+var ErrUnknownCity = errors.New("unknown city")
+
+// ErrTooManyDays is returned (wrapped) by providers asked for more forecast
+// days than they have.
+var ErrTooManyDays = errors.New("too many forecast days")
+
+// ErrUnsupportedUnit is returned (wrapped) for temperature units other than
+// celsius and fahrenheit.
+var ErrUnsupportedUnit = errors.New("unsupported unit")
+
+// RateLimitError is returned by providers when the weather service refuses
+// requests for now.
+type RateLimitError struct {
+	RetryAfter time.Duration // How long the service asked us to wait; 0 if it didn't say
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited; retry after %s", e.RetryAfter)
+	}
+	return "rate limited"
+}
+
+// Provider used by get_weather and get_forecast.
+var weatherProvider WeatherProvider = RandomWeatherProvider{}
+
+// SetWeatherProvider sets the provider used by the weather tools.
+func SetWeatherProvider(p WeatherProvider) {
+	weatherProvider = p
+}
+
+// WeatherProviderFromEnv builds the provider selected by WEATHER_PROVIDER:
+//   - "random" (or unset): RandomWeatherProvider
+//   - "fixture": FixtureWeatherProvider loaded from WEATHER_FIXTURE_FILE
+//   - "openmeteo": OpenMeteoWeatherProvider; OPEN_METEO_GEOCODING_URL and
+//     OPEN_METEO_FORECAST_URL optionally override the API endpoints
+func WeatherProviderFromEnv() (WeatherProvider, error) {
+	switch name := os.Getenv("WEATHER_PROVIDER"); name {
+	case "", "random":
+		return RandomWeatherProvider{}, nil
+	case "fixture":
+		path := os.Getenv("WEATHER_FIXTURE_FILE")
+		if path == "" {
+			return nil, errors.New("WEATHER_PROVIDER=fixture requires WEATHER_FIXTURE_FILE")
+		}
+		return LoadFixtureWeatherProvider(path)
+	case "openmeteo":
+		return &OpenMeteoWeatherProvider{
+			GeocodingURL: os.Getenv("OPEN_METEO_GEOCODING_URL"),
+			ForecastURL:  os.Getenv("OPEN_METEO_FORECAST_URL"),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown WEATHER_PROVIDER %q (want random, fixture or openmeteo)", name)
+	}
+}
+
+// =============================================================================
+// Random provider
+// =============================================================================
+
+// RandomWeatherProvider fabricates plausible weather for any city.
+type RandomWeatherProvider struct{}
+
+// CurrentWeather returns random conditions for city.
+func (RandomWeatherProvider) CurrentWeather(_ context.Context, city string) (*Weather, error) {
+	conditions := []string{"sunny", "cloudy", "rainy", "windy"}
+	return &Weather{
+		Location:    city,
+		Temperature: float64(15 + rand.Intn(20)),
+		Unit:        "celsius",
+		Conditions:  conditions[rand.Intn(len(conditions))],
+		Humidity:    40 + rand.Intn(40),
+	}, nil
+}
+
+// Forecast returns random daily conditions for city.
+func (RandomWeatherProvider) Forecast(_ context.Context, city string, days int) (*Forecast, error) {
+	conditions := []string{"sunny", "cloudy", "rainy", "windy"}
+	f := &Forecast{Location: city, Unit: "celsius", Days: []ForecastDay{}}
+	today := time.Now()
+	for i := range days {
+		low := 5 + rand.Intn(15)
+		f.Days = append(f.Days, ForecastDay{
+			Date:                today.AddDate(0, 0, i).Format(time.DateOnly),
+			High:                float64(low + 3 + rand.Intn(10)),
+			Low:                 float64(low),
+			PrecipitationChance: rand.Intn(101),
+			Conditions:          conditions[rand.Intn(len(conditions))],
+		})
+	}
+	return f, nil
+}
+
+// =============================================================================
+// Fixture provider
+// =============================================================================
+
+// FixtureWeatherProvider serves weather from a fixed table keyed by city
+// name (case-insensitive).
+type FixtureWeatherProvider struct {
+	cities map[string]FixtureCity
+}
+
+// FixtureCity is one city's entry in a fixture: its current conditions and,
+// optionally, its daily forecast (first entry is today).
+type FixtureCity struct {
+	Weather
+	Forecast []ForecastDay `json:"forecast,omitempty"`
+}
+
+// NewFixtureWeatherProvider returns a provider serving the given weather,
+// keyed by city name. Empty Location fields default to the key.
+func NewFixtureWeatherProvider(cities map[string]FixtureCity) *FixtureWeatherProvider {
+	p := &FixtureWeatherProvider{cities: make(map[string]FixtureCity, len(cities))}
+	for city, c := range cities {
+		if c.Location == "" {
+			c.Location = city
+		}
+		c.Unit = "celsius"
+		p.cities[strings.ToLower(city)] = c
+	}
+	return p
+}
+
+// LoadFixtureWeatherProvider reads a JSON object mapping city names to
+// FixtureCity entries, for example:
+//
+//	{"London": {"temperature": 12, "conditions": "rainy", "humidity": 81,
+//	  "forecast": [{"date": "2025-01-01", "high": 14, "low": 8,
+//	    "precipitationChance": 60, "conditions": "rainy"}]}}
+func LoadFixtureWeatherProvider(path string) (*FixtureWeatherProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cities map[string]FixtureCity
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return nil, fmt.Errorf("parsing weather fixture %s: %w", path, err)
+	}
+	return NewFixtureWeatherProvider(cities), nil
+}
+
+// CurrentWeather returns the fixture entry for city.
+func (p *FixtureWeatherProvider) CurrentWeather(_ context.Context, city string) (*Weather, error) {
+	c, ok := p.cities[strings.ToLower(strings.TrimSpace(city))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCity, city)
+	}
+	w := c.Weather
+	return &w, nil
+}
+
+// Forecast returns the first days entries of the fixture's forecast for city.
+func (p *FixtureWeatherProvider) Forecast(_ context.Context, city string, days int) (*Forecast, error) {
+	c, ok := p.cities[strings.ToLower(strings.TrimSpace(city))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCity, city)
+	}
+	if len(c.Forecast) < days {
+		return nil, fmt.Errorf("%w: the fixture has %d for %s, %d requested", ErrTooManyDays, len(c.Forecast), city, days)
+	}
+	return &Forecast{
+		Location: c.Location,
+		Unit:     "celsius",
+		Days:     slices.Clone(c.Forecast[:days]),
+	}, nil
+}
+
+// =============================================================================
+// Open-Meteo provider
+// =============================================================================
+
+// Default Open-Meteo endpoints. See https://open-meteo.com/en/docs
+const (
+	defaultGeocodingURL = "https://geocoding-api.open-meteo.com/v1/search"
+	defaultForecastURL  = "https://api.open-meteo.com/v1/forecast"
+)
+
+// OpenMeteoWeatherProvider fetches real weather from Open-Meteo: the city is
+// resolved to coordinates with the geocoding API, then current conditions
+// or daily forecasts are read from the forecast API.
+type OpenMeteoWeatherProvider struct {
+	// GeocodingURL and ForecastURL override the API endpoints (e.g. to point
+	// at a local fake server). Empty means the public Open-Meteo API.
+	GeocodingURL string
+	ForecastURL  string
+	// Client is the HTTP client to use. Nil means a client with a 10s timeout.
+	Client *http.Client
+}
+
+var defaultWeatherClient = &http.Client{Timeout: 10 * time.Second}
+
+// CurrentWeather looks up city and returns its current conditions.
+func (p *OpenMeteoWeatherProvider) CurrentWeather(ctx context.Context, city string) (*Weather, error) {
+	place, err := p.geocode(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+
+	var forecast struct {
+		Current struct {
+			Temperature float64 `json:"temperature_2m"`
+			Humidity    float64 `json:"relative_humidity_2m"`
+			WeatherCode int     `json:"weather_code"`
+		} `json:"current"`
+	}
+	q := url.Values{}
+	q.Set("latitude", fmt.Sprint(place.Latitude))
+	q.Set("longitude", fmt.Sprint(place.Longitude))
+	q.Set("current", "temperature_2m,relative_humidity_2m,weather_code")
+	if err := p.get(ctx, orDefault(p.ForecastURL, defaultForecastURL), q, &forecast); err != nil {
+		return nil, fmt.Errorf("fetching forecast: %w", err)
+	}
+
+	return &Weather{
+		Location:    place.displayName(),
+		Temperature: forecast.Current.Temperature,
+		Unit:        "celsius",
+		Conditions:  weatherCodeConditions(forecast.Current.WeatherCode),
+		Humidity:    int(math.Round(forecast.Current.Humidity)),
+	}, nil
+}
+
+// Forecast looks up city and returns its daily forecast.
+func (p *OpenMeteoWeatherProvider) Forecast(ctx context.Context, city string, days int) (*Forecast, error) {
+	place, err := p.geocode(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+
+	var forecast struct {
+		Daily struct {
+			Time                []string  `json:"time"`
+			High                []float64 `json:"temperature_2m_max"`
+			Low                 []float64 `json:"temperature_2m_min"`
+			PrecipitationChance []float64 `json:"precipitation_probability_max"`
+			WeatherCode         []int     `json:"weather_code"`
+		} `json:"daily"`
+	}
+	q := url.Values{}
+	q.Set("latitude", fmt.Sprint(place.Latitude))
+	q.Set("longitude", fmt.Sprint(place.Longitude))
+	q.Set("daily", "temperature_2m_max,temperature_2m_min,precipitation_probability_max,weather_code")
+	q.Set("forecast_days", fmt.Sprint(days))
+	q.Set("timezone", "auto")
+	if err := p.get(ctx, orDefault(p.ForecastURL, defaultForecastURL), q, &forecast); err != nil {
+		return nil, fmt.Errorf("fetching forecast: %w", err)
+	}
+
+	daily := forecast.Daily
+	n := len(daily.Time)
+	if len(daily.High) != n || len(daily.Low) != n || len(daily.PrecipitationChance) != n || len(daily.WeatherCode) != n {
+		return nil, errors.New("fetching forecast: daily arrays have mismatched lengths")
+	}
+	f := &Forecast{Location: place.displayName(), Unit: "celsius", Days: []ForecastDay{}}
+	for i := range min(n, days) {
+		f.Days = append(f.Days, ForecastDay{
+			Date:                daily.Time[i],
+			High:                daily.High[i],
+			Low:                 daily.Low[i],
+			PrecipitationChance: int(math.Round(daily.PrecipitationChance[i])),
+			Conditions:          weatherCodeConditions(daily.WeatherCode[i]),
+		})
+	}
+	return f, nil
+}
+
+type openMeteoPlace struct {
+	Name      string  `json:"name"`
+	Country   string  `json:"country"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (p *openMeteoPlace) displayName() string {
+	if p.Country == "" {
+		return p.Name
+	}
+	return p.Name + ", " + p.Country
+}
+
+func (p *OpenMeteoWeatherProvider) geocode(ctx context.Context, city string) (*openMeteoPlace, error) {
+	var result struct {
+		Results []openMeteoPlace `json:"results"`
+	}
+	q := url.Values{}
+	q.Set("name", city)
+	q.Set("count", "1")
+	if err := p.get(ctx, orDefault(p.GeocodingURL, defaultGeocodingURL), q, &result); err != nil {
+		return nil, fmt.Errorf("looking up city: %w", err)
+	}
+	if len(result.Results) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCity, city)
+	}
+	return &result.Results[0], nil
+}
+
+func (p *OpenMeteoWeatherProvider) get(ctx context.Context, endpoint string, query url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	client := p.Client
+	if client == nil {
+		client = defaultWeatherClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// Retry-After may also be an HTTP date, which we don't bother with.
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &RateLimitError{RetryAfter: time.Duration(max(seconds, 0)) * time.Second}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", endpoint, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// weatherCodeConditions maps a WMO weather code to a short description.
+// See the "WMO Weather interpretation codes" table at https://open-meteo.com/en/docs
+func weatherCodeConditions(code int) string {
+	switch {
+	case code == 0:
+		return "clear"
+	case code <= 2:
+		return "partly cloudy"
+	case code == 3:
+		return "cloudy"
+	case code == 45 || code == 48:
+		return "foggy"
+	case code >= 51 && code <= 57:
+		return "drizzle"
+	case code >= 61 && code <= 67, code >= 80 && code <= 82:
+		return "rainy"
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		return "snowy"
+	case code >= 95:
+		return "thunderstorm"
+	default:
+		return "unknown"
+	}
+}
+
+// =============================================================================
+// Units
+// =============================================================================
+
+// convertTemperature converts a Celsius reading to unit ("celsius" or
+// "fahrenheit"), rounding to the nearest degree.
+func convertTemperature(celsius float64, unit string) (float64, error) {
+	switch unit {
+	case "celsius":
+		return math.Round(celsius), nil
+	case "fahrenheit":
+		return math.Round(celsius*9/5 + 32), nil
+	default:
+		return 0, fmt.Errorf("%w %q (want celsius or fahrenheit)", ErrUnsupportedUnit, unit)
+	}
+}
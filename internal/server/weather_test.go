@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SamMorrowDrums/mcp-go-starter/internal/validate"
+)
+
+// fakeOpenMeteo serves both Open-Meteo APIs: geocoding knows only London, and
+// the forecast API answers with current conditions or daily forecasts.
+func fakeOpenMeteo(t *testing.T, forecast http.HandlerFunc) *OpenMeteoWeatherProvider {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geocoding", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "London" {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`{"results": [{"name": "London", "country": "United Kingdom", "latitude": 51.5, "longitude": -0.12}]}`))
+	})
+	mux.HandleFunc("/forecast", forecast)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return &OpenMeteoWeatherProvider{GeocodingURL: srv.URL + "/geocoding", ForecastURL: srv.URL + "/forecast"}
+}
+
+func TestOpenMeteoCurrentWeather(t *testing.T) {
+	p := fakeOpenMeteo(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("latitude"); got != "51.5" {
+			t.Errorf("latitude = %q, want 51.5", got)
+		}
+		w.Write([]byte(`{"current": {"temperature_2m": 21.7, "relative_humidity_2m": 64.4, "weather_code": 61}}`))
+	})
+
+	w, err := p.CurrentWeather(context.Background(), "London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Weather{Location: "London, United Kingdom", Temperature: 21.7, Unit: "celsius", Conditions: "rainy", Humidity: 64}
+	if *w != want {
+		t.Errorf("CurrentWeather = %+v, want %+v", *w, want)
+	}
+}
+
+func TestOpenMeteoForecast(t *testing.T) {
+	p := fakeOpenMeteo(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("forecast_days"); got != "2" {
+			t.Errorf("forecast_days = %q, want 2", got)
+		}
+		w.Write([]byte(`{"daily": {
+			"time": ["2025-01-01", "2025-01-02"],
+			"temperature_2m_max": [14.2, 15.6],
+			"temperature_2m_min": [8.1, 9.5],
+			"precipitation_probability_max": [60, 10],
+			"weather_code": [63, 0]}}`))
+	})
+
+	f, err := p.Forecast(context.Background(), "London", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Days) != 2 {
+		t.Fatalf("got %d days, want 2", len(f.Days))
+	}
+	want := ForecastDay{Date: "2025-01-02", High: 15.6, Low: 9.5, PrecipitationChance: 10, Conditions: "clear"}
+	if f.Days[1] != want {
+		t.Errorf("day 2 = %+v, want %+v", f.Days[1], want)
+	}
+}
+
+func TestOpenMeteoErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		city    string
+		handler http.HandlerFunc
+		check   func(t *testing.T, err error)
+	}{
+		{
+			name: "unknown city",
+			city: "Atlantis",
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, ErrUnknownCity) {
+					t.Errorf("err = %v, want ErrUnknownCity", err)
+				}
+			},
+		},
+		{
+			name: "rate limited",
+			city: "London",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			check: func(t *testing.T, err error) {
+				var limited *RateLimitError
+				if !errors.As(err, &limited) || limited.RetryAfter != 30*time.Second {
+					t.Errorf("err = %v, want a RateLimitError with RetryAfter 30s", err)
+				}
+			},
+		},
+		{
+			name: "malformed body",
+			city: "London",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"current": `))
+			},
+			check: func(t *testing.T, err error) {
+				var limited *RateLimitError
+				if err == nil || errors.Is(err, ErrUnknownCity) || errors.As(err, &limited) {
+					t.Errorf("err = %v, want a decoding error", err)
+				}
+			},
+		},
+		{
+			name: "server error",
+			city: "London",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "oops", http.StatusInternalServerError)
+			},
+			check: func(t *testing.T, err error) {
+				if err == nil {
+					t.Error("err = nil, want an error")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.handler
+			if handler == nil {
+				handler = func(w http.ResponseWriter, r *http.Request) {
+					t.Error("forecast API called for an unknown city")
+				}
+			}
+			p := fakeOpenMeteo(t, handler)
+			_, err := p.CurrentWeather(context.Background(), tt.city)
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestWeatherError(t *testing.T) {
+	_, unitErr := convertTemperature(20, "kelvin")
+	_, daysErr := NewFixtureWeatherProvider(map[string]FixtureCity{"London": {}}).Forecast(context.Background(), "London", 3)
+	tests := []struct {
+		name      string
+		err       error
+		code      ErrorCode
+		retryable bool
+	}{
+		{"unknown city", ErrUnknownCity, CodeNotFound, false},
+		{"unsupported unit", unitErr, CodeInvalidArgument, false},
+		{"too many days", daysErr, CodeInvalidArgument, false},
+		{"rate limited", &RateLimitError{RetryAfter: time.Minute}, CodeRateLimited, true},
+		{"unreachable", errors.New("connection refused"), CodeInternal, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := weatherError("London", tt.err)
+			if e.Code != tt.code || e.Retryable != tt.retryable {
+				t.Errorf("weatherError(%v) = %s (retryable %v), want %s (retryable %v)", tt.err, e.Code, e.Retryable, tt.code, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestConvertTemperatureRoundsOnce(t *testing.T) {
+	// 21.7°C is 71.06°F. Rounding to 22°C first would give 72°F.
+	if got, _ := convertTemperature(21.7, "fahrenheit"); got != 71 {
+		t.Errorf("convertTemperature(21.7, fahrenheit) = %v, want 71", got)
+	}
+	if got, _ := convertTemperature(21.7, "celsius"); got != 22 {
+		t.Errorf("convertTemperature(21.7, celsius) = %v, want 22", got)
+	}
+}
+
+// The output schemas must accept the Go types the tools return, including
+// temperatures that aren't whole numbers.
+func TestWeatherOutputSchemasMatchTypes(t *testing.T) {
+	cs := connect(t, NewServer(), nil)
+	outputs := map[string]any{
+		"get_weather":  Weather{Location: "London", Temperature: 21.5, Unit: "celsius", Conditions: "Sunny", Humidity: 40},
+		"get_forecast": Forecast{Location: "London", Unit: "celsius", Days: []ForecastDay{{Date: "2026-01-01", High: 8.5, Low: -1.5, Conditions: "Cloudy"}}},
+	}
+	for tool, err := range cs.Tools(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, ok := outputs[tool.Name]
+		if !ok {
+			continue
+		}
+		delete(outputs, tool.Name)
+		schema, err := validate.Schema(tool.OutputSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := validate.Schema(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if violations := validate.Check(schema, value); len(violations) > 0 {
+			t.Errorf("%s output breaks its schema: %v", tool.Name, violations)
+		}
+	}
+	for tool := range outputs {
+		t.Errorf("%s not listed", tool)
+	}
+}
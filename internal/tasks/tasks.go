@@ -0,0 +1,292 @@
+// Package tasks runs tool work in the background.
+//
+// WHY TASKS?
+// A normal tool call holds the request open until the work is done. For
+// long-running work that is awkward: the client has to keep the connection
+// alive and can't do anything else with the result in the meantime. A task
+// manager lets a tool start the work, return a task ID immediately, and let
+// the client poll for status, fetch the result later, or cancel it.
+//
+// The shape of Task and its Status values follow the MCP tasks utility
+// (protocol version 2025-11-25). The Go SDK used here does not implement the
+// tasks/* methods yet, so the server exposes the manager through ordinary
+// tools and resources instead; see internal/server/tasks.go.
+package tasks
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Status is the lifecycle state of a task.
+type Status string
+
+// Task statuses, as defined by the MCP tasks utility.
+const (
+	Working   Status = "working"
+	Completed Status = "completed"
+	Failed    Status = "failed"
+	Cancelled Status = "cancelled"
+)
+
+// Terminal reports whether a task in this status has finished.
+func (s Status) Terminal() bool {
+	return s != Working
+}
+
+// Task is a snapshot of a background task.
+type Task struct {
+	ID            string    `json:"taskId"`
+	Name          string    `json:"name"`
+	Status        Status    `json:"status"`
+	StatusMessage string    `json:"statusMessage,omitempty"`
+	Progress      float64   `json:"progress"`
+	Total         float64   `json:"total,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt"`
+	// TTL is how long, in milliseconds, the task and its result are kept
+	// after it finishes.
+	TTL int64 `json:"ttl"`
+}
+
+// Func is the work a task performs. It should return promptly when ctx is
+// cancelled; any result it returns then is kept as a partial result.
+// report updates the task's progress and status message.
+type Func func(ctx context.Context, report ProgressFunc) (*mcp.CallToolResult, error)
+
+// ProgressFunc records a task's progress towards total.
+type ProgressFunc func(progress, total float64, message string)
+
+// Errors returned by Manager methods.
+var (
+	ErrNotFound = errors.New("task not found")
+	ErrFinished = errors.New("task already finished")
+)
+
+// Options configure a Manager. The zero value uses the defaults.
+type Options struct {
+	// TTL is how long finished tasks are retained. Default: one hour.
+	TTL time.Duration
+	// MaxRetained caps the number of finished tasks kept; the oldest are
+	// dropped first. Running tasks are never dropped. Default: 100.
+	MaxRetained int
+}
+
+// Manager runs and tracks tasks. It is safe for concurrent use.
+type Manager struct {
+	ttl         time.Duration
+	maxRetained int
+
+	mu    sync.Mutex
+	tasks map[string]*entry
+}
+
+type entry struct {
+	task       Task
+	result     *mcp.CallToolResult
+	cancel     context.CancelFunc
+	done       chan struct{}
+	finishedAt time.Time
+}
+
+// NewManager returns an empty Manager. opts may be nil.
+func NewManager(opts *Options) *Manager {
+	m := &Manager{
+		ttl:         time.Hour,
+		maxRetained: 100,
+		tasks:       make(map[string]*entry),
+	}
+	if opts != nil {
+		if opts.TTL > 0 {
+			m.ttl = opts.TTL
+		}
+		if opts.MaxRetained > 0 {
+			m.maxRetained = opts.MaxRetained
+		}
+	}
+	return m
+}
+
+// Start runs fn in a new goroutine and returns the new task. The task is not
+// tied to any request context: it runs until fn returns or it is cancelled.
+func (m *Manager) Start(name string, fn Func) Task {
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	e := &entry{
+		task: Task{
+			ID:            rand.Text(),
+			Name:          name,
+			Status:        Working,
+			CreatedAt:     now,
+			LastUpdatedAt: now,
+			TTL:           m.ttl.Milliseconds(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.tasks[e.task.ID] = e
+	task := e.task
+	m.mu.Unlock()
+
+	report := func(progress, total float64, message string) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if e.task.Status.Terminal() {
+			return
+		}
+		e.task.Progress = progress
+		e.task.Total = total
+		e.task.StatusMessage = message
+		e.task.LastUpdatedAt = time.Now()
+	}
+
+	go func() {
+		defer cancel()
+		result, err := fn(ctx, report)
+		m.finish(ctx, e, result, err)
+	}()
+	return task
+}
+
+func (m *Manager) finish(ctx context.Context, e *entry, result *mcp.CallToolResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	switch {
+	case ctx.Err() != nil:
+		e.task.Status = Cancelled
+		e.task.StatusMessage = "Cancelled"
+	case err != nil:
+		e.task.Status = Failed
+		e.task.StatusMessage = err.Error()
+	case result != nil && result.IsError:
+		e.task.Status = Failed
+		e.task.StatusMessage = "Tool returned an error"
+	default:
+		e.task.Status = Completed
+		e.task.StatusMessage = ""
+		e.task.Progress = e.task.Total
+	}
+	e.task.LastUpdatedAt = now
+	e.result = result
+	e.finishedAt = now
+	close(e.done)
+}
+
+// Get returns the current state of the task with the given ID.
+func (m *Manager) Get(id string) (Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+	e, ok := m.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return e.task, nil
+}
+
+// Result waits for the task to finish and returns its final state and
+// result. The result may be nil if the task failed or was cancelled before
+// producing one. Result returns ctx's error if ctx is done first.
+func (m *Manager) Result(ctx context.Context, id string) (Task, *mcp.CallToolResult, error) {
+	m.mu.Lock()
+	m.prune()
+	e, ok := m.tasks[id]
+	m.mu.Unlock()
+	if !ok {
+		return Task{}, nil, ErrNotFound
+	}
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return Task{}, nil, ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return e.task, e.result, nil
+}
+
+// List returns all retained tasks, oldest first.
+func (m *Manager) List() []Task {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+	tasks := make([]Task, 0, len(m.tasks))
+	for _, e := range m.tasks {
+		tasks = append(tasks, e.task)
+	}
+	slices.SortFunc(tasks, func(a, b Task) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return tasks
+}
+
+// Cancel asks a running task to stop and waits for it to do so. It returns
+// ErrFinished if the task had already finished.
+func (m *Manager) Cancel(ctx context.Context, id string) (Task, error) {
+	m.mu.Lock()
+	e, ok := m.tasks[id]
+	if ok && e.task.Status.Terminal() {
+		m.mu.Unlock()
+		return Task{}, ErrFinished
+	}
+	m.mu.Unlock()
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+
+	e.cancel()
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return Task{}, ctx.Err()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return e.task, nil
+}
+
+// Close cancels every running task.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.tasks {
+		e.cancel()
+	}
+}
+
+// prune drops finished tasks that have outlived the TTL, then the oldest
+// finished tasks beyond MaxRetained. m.mu must be held.
+func (m *Manager) prune() {
+	now := time.Now()
+	var finished []*entry
+	for id, e := range m.tasks {
+		if !e.task.Status.Terminal() {
+			continue
+		}
+		if now.Sub(e.finishedAt) > m.ttl {
+			delete(m.tasks, id)
+			continue
+		}
+		finished = append(finished, e)
+	}
+	if excess := len(finished) - m.maxRetained; excess > 0 {
+		slices.SortFunc(finished, func(a, b *entry) int {
+			return a.finishedAt.Compare(b.finishedAt)
+		})
+		for _, e := range finished[:excess] {
+			delete(m.tasks, e.task.ID)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// wait returns the task's final state and result.
+func wait(t *testing.T, m *Manager, id string) (Task, *mcp.CallToolResult) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	task, res, err := m.Result(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task, res
+}
+
+// blocked starts a task that runs until cancelled.
+func blocked(m *Manager, name string) Task {
+	return m.Start(name, func(ctx context.Context, report ProgressFunc) (*mcp.CallToolResult, error) {
+		report(1, 4, "Waiting")
+		<-ctx.Done()
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "partial"}}}, nil
+	})
+}
+
+func TestTaskOutcomes(t *testing.T) {
+	ok := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}
+	tests := []struct {
+		name    string
+		fn      Func
+		status  Status
+		message string
+		result  bool
+	}{
+		{
+			name: "completed",
+			fn: func(_ context.Context, report ProgressFunc) (*mcp.CallToolResult, error) {
+				report(1, 2, "Halfway")
+				return ok, nil
+			},
+			status: Completed,
+			result: true,
+		},
+		{
+			name: "failed with an error",
+			fn: func(context.Context, ProgressFunc) (*mcp.CallToolResult, error) {
+				return nil, errors.New("boom")
+			},
+			status:  Failed,
+			message: "boom",
+		},
+		{
+			name: "failed with an error result",
+			fn: func(context.Context, ProgressFunc) (*mcp.CallToolResult, error) {
+				return &mcp.CallToolResult{IsError: true}, nil
+			},
+			status:  Failed,
+			message: "Tool returned an error",
+			result:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(nil)
+			started := m.Start("t", tt.fn)
+			if started.Status != Working {
+				t.Errorf("new task status = %s, want %s", started.Status, Working)
+			}
+			task, res := wait(t, m, started.ID)
+			if task.Status != tt.status || task.StatusMessage != tt.message {
+				t.Errorf("task = %s %q, want %s %q", task.Status, task.StatusMessage, tt.status, tt.message)
+			}
+			if (res != nil) != tt.result {
+				t.Errorf("result = %v, want one: %v", res, tt.result)
+			}
+			if task.Status == Completed && task.Progress != task.Total {
+				t.Errorf("completed task progress = %v/%v, want all done", task.Progress, task.Total)
+			}
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	m := NewManager(nil)
+	task := blocked(m, "t")
+	defer m.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := m.Get(task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.StatusMessage == "Waiting" {
+			if got.Status != Working || got.Progress != 1 || got.Total != 4 {
+				t.Errorf("task = %+v, want working at 1/4", got)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("progress was never reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	m := NewManager(nil)
+	task := blocked(m, "t")
+
+	cancelled, err := m.Cancel(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.Status != Cancelled {
+		t.Errorf("status after Cancel = %s, want %s", cancelled.Status, Cancelled)
+	}
+	// The partial result is kept.
+	if _, res := wait(t, m, task.ID); res == nil {
+		t.Error("cancelled task has no partial result")
+	}
+	if _, err := m.Cancel(context.Background(), task.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("cancelling again: err = %v, want ErrFinished", err)
+	}
+}
+
+func TestUnknownTask(t *testing.T) {
+	m := NewManager(nil)
+	if _, err := m.Get("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get: err = %v, want ErrNotFound", err)
+	}
+	if _, _, err := m.Result(context.Background(), "nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Result: err = %v, want ErrNotFound", err)
+	}
+	if _, err := m.Cancel(context.Background(), "nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestResultHonoursContext(t *testing.T) {
+	m := NewManager(nil)
+	task := blocked(m, "t")
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := m.Result(ctx, task.ID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	m := NewManager(nil)
+	a, b := blocked(m, "a"), blocked(m, "b")
+	m.Close()
+	for _, id := range []string{a.ID, b.ID} {
+		if task, _ := wait(t, m, id); task.Status != Cancelled {
+			t.Errorf("task %s status = %s after Close, want %s", task.Name, task.Status, Cancelled)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	m := NewManager(nil)
+	defer m.Close()
+	var ids []string
+	for _, name := range []string{"first", "second", "third"} {
+		ids = append(ids, blocked(m, name).ID)
+		time.Sleep(time.Millisecond) // Distinct creation times
+	}
+	list := m.List()
+	if len(list) != 3 {
+		t.Fatalf("List returned %d tasks, want 3", len(list))
+	}
+	for i, task := range list {
+		if task.ID != ids[i] {
+			t.Errorf("List()[%d] = %s, want %s (oldest first)", i, task.Name, []string{"first", "second", "third"}[i])
+		}
+	}
+}
+
+func TestPruneMaxRetained(t *testing.T) {
+	m := NewManager(&Options{MaxRetained: 2})
+	running := blocked(m, "running")
+	defer m.Close()
+	var finished []string
+	for range 3 {
+		task := m.Start("quick", func(context.Context, ProgressFunc) (*mcp.CallToolResult, error) { return nil, nil })
+		wait(t, m, task.ID)
+		finished = append(finished, task.ID)
+		time.Sleep(time.Millisecond) // Distinct finish times
+	}
+
+	if _, err := m.Get(finished[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oldest finished task: err = %v, want ErrNotFound", err)
+	}
+	for _, id := range append(finished[1:], running.ID) {
+		if _, err := m.Get(id); err != nil {
+			t.Errorf("task %s was dropped: %v", id, err)
+		}
+	}
+}
+
+func TestPruneTTL(t *testing.T) {
+	m := NewManager(&Options{TTL: 10 * time.Millisecond})
+	running := blocked(m, "running")
+	defer m.Close()
+	quick := m.Start("quick", func(context.Context, ProgressFunc) (*mcp.CallToolResult, error) { return nil, nil })
+	if task, _ := wait(t, m, quick.ID); task.TTL != 10 {
+		t.Errorf("TTL = %dms, want 10", task.TTL)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := m.Get(quick.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired task: err = %v, want ErrNotFound", err)
+	}
+	// Running tasks never expire.
+	if _, err := m.Get(running.ID); err != nil {
+		t.Errorf("running task: %v", err)
+	}
+}
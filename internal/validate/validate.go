@@ -0,0 +1,287 @@
+// Package validate checks values against the constraints declared in a JSON
+// Schema, such as a tool's input schema, and reports every problem it finds
+// rather than stopping at the first.
+//
+// It understands the keywords tool schemas use to constrain their inputs:
+//   - Any value:  type, enum, const
+//   - Strings:    minLength, maxLength, pattern
+//   - Numbers:    minimum, maximum, exclusiveMinimum, exclusiveMaximum
+//   - Arrays:     minItems, maxItems, items
+//   - Objects:    required, properties, additionalProperties, propertyNames
+//
+// Other keywords are ignored, so a schema using them may accept values a
+// full JSON Schema validator would reject.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Violation is one broken constraint.
+type Violation struct {
+	// Field is the path to the offending value, such as "steps" or
+	// "series[1].values[0]". It is empty for the value as a whole.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.Message
+	}
+	return v.Field + " " + v.Message
+}
+
+// Schema decodes a schema given as any JSON-encodable value, such as a
+// map[string]any or json.RawMessage, into the form Check expects.
+func Schema(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("schema is not a JSON object: %w", err)
+	}
+	return schema, nil
+}
+
+// Check returns every violation of schema by value, in a stable order. Both
+// must be as decoded by encoding/json into an any: maps, slices, float64s,
+// strings, bools and nils.
+func Check(schema map[string]any, value any) []Violation {
+	var c checker
+	c.check("", schema, value)
+	return c.violations
+}
+
+type checker struct {
+	violations []Violation
+}
+
+func (c *checker) add(field, format string, args ...any) {
+	c.violations = append(c.violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) check(field string, schema map[string]any, v any) {
+	if !c.checkType(field, schema["type"], v) {
+		return // The other constraints assume the right type
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, equal(v)) {
+		c.add(field, "must be one of %s", list(enum))
+	}
+	if want, ok := schema["const"]; ok && !equal(v)(want) {
+		c.add(field, "must be %s", show(want))
+	}
+
+	switch v := v.(type) {
+	case string:
+		c.checkString(field, schema, v)
+	case float64:
+		c.checkNumber(field, schema, v)
+	case []any:
+		c.checkArray(field, schema, v)
+	case map[string]any:
+		c.checkObject(field, schema, v)
+	}
+}
+
+// checkType reports whether v has one of the types allowed by t, which is a
+// type name, a list of them or nil for any type.
+func (c *checker) checkType(field string, t any, v any) bool {
+	var types []string
+	switch t := t.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+	if len(types) == 0 || slices.ContainsFunc(types, func(name string) bool { return hasType(v, name) }) {
+		return true
+	}
+	var wants []string
+	for _, name := range types {
+		wants = append(wants, typeNames[name])
+	}
+	c.add(field, "must be %s", strings.Join(wants, " or "))
+	return false
+}
+
+var typeNames = map[string]string{
+	"string":  "a string",
+	"number":  "a number",
+	"integer": "a whole number",
+	"boolean": "true or false",
+	"array":   "an array",
+	"object":  "an object",
+	"null":    "null",
+}
+
+func hasType(v any, name string) bool {
+	switch v := v.(type) {
+	case string:
+		return name == "string"
+	case float64:
+		return name == "number" || name == "integer" && v == math.Trunc(v)
+	case bool:
+		return name == "boolean"
+	case []any:
+		return name == "array"
+	case map[string]any:
+		return name == "object"
+	case nil:
+		return name == "null"
+	}
+	return false
+}
+
+func (c *checker) checkString(field string, schema map[string]any, s string) {
+	n := float64(utf8.RuneCountInString(s))
+	if min, ok := schema["minLength"].(float64); ok && n < min {
+		if min == 1 {
+			c.add(field, "must not be empty")
+		} else {
+			c.add(field, "must be at least %s characters long", num(min))
+		}
+	}
+	if max, ok := schema["maxLength"].(float64); ok && n > max {
+		c.add(field, "must be at most %s characters long", num(max))
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re := compile(pattern); re != nil && !re.MatchString(s) {
+			c.add(field, "must match the pattern %s", pattern)
+		}
+	}
+}
+
+func (c *checker) checkNumber(field string, schema map[string]any, n float64) {
+	if min, ok := schema["minimum"].(float64); ok && n < min {
+		c.add(field, "must be at least %s", num(min))
+	}
+	if max, ok := schema["maximum"].(float64); ok && n > max {
+		c.add(field, "must be at most %s", num(max))
+	}
+	if min, ok := schema["exclusiveMinimum"].(float64); ok && n <= min {
+		c.add(field, "must be greater than %s", num(min))
+	}
+	if max, ok := schema["exclusiveMaximum"].(float64); ok && n >= max {
+		c.add(field, "must be less than %s", num(max))
+	}
+}
+
+func (c *checker) checkArray(field string, schema map[string]any, items []any) {
+	if min, ok := schema["minItems"].(float64); ok && float64(len(items)) < min {
+		c.add(field, "must have at least %s items", num(min))
+	}
+	if max, ok := schema["maxItems"].(float64); ok && float64(len(items)) > max {
+		c.add(field, "must have at most %s items", num(max))
+	}
+	if itemSchema, ok := schema["items"].(map[string]any); ok {
+		for i, item := range items {
+			c.check(fmt.Sprintf("%s[%d]", field, i), itemSchema, item)
+		}
+	}
+}
+
+func (c *checker) checkObject(field string, schema map[string]any, obj map[string]any) {
+	props, _ := schema["properties"].(map[string]any)
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := obj[name]; !ok {
+					c.add(join(field, name), "is required")
+				}
+			}
+		}
+	}
+	names, _ := schema["propertyNames"].(map[string]any)
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		if names != nil {
+			var nc checker
+			nc.check("", names, name)
+			for _, v := range nc.violations {
+				c.add(join(field, name), "is not an allowed name: it %s", v.Message)
+			}
+		}
+		if prop, ok := props[name].(map[string]any); ok {
+			c.check(join(field, name), prop, obj[name])
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				c.add(join(field, name), "is not a known property")
+			}
+		case map[string]any:
+			c.check(join(field, name), extra, obj[name])
+		}
+	}
+}
+
+// join appends property name to the path field.
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// Compiled patterns, by source. A pattern that doesn't compile is stored as
+// nil and ignored.
+var patterns sync.Map
+
+func compile(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	patterns.Store(pattern, re)
+	return re
+}
+
+// equal returns a function reporting whether a JSON value equals v.
+func equal(v any) func(any) bool {
+	return func(w any) bool { return reflect.DeepEqual(v, w) }
+}
+
+func list(values []any) string {
+	var parts []string
+	for _, v := range values {
+		parts = append(parts, show(v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// show formats a JSON value for a message.
+func show(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return num(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// num formats n without an exponent, so that 1000000 isn't 1e+06.
+func num(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,146 @@
+package validate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// decode decodes JSON as Check expects its arguments.
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("%s: %v", s, err)
+	}
+	return v
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []Violation
+	}{
+		{
+			name:   "valid",
+			schema: `{"type": "object", "properties": {"n": {"type": "integer", "minimum": 1}}, "required": ["n"]}`,
+			value:  `{"n": 3}`,
+		},
+		{
+			name:   "whole number",
+			schema: `{"type": "integer"}`,
+			value:  `2.0`,
+		},
+		{
+			name:   "fraction",
+			schema: `{"type": "integer"}`,
+			value:  `2.5`,
+			want:   []Violation{{Message: "must be a whole number"}},
+		},
+		{
+			name:   "integer range without exponent",
+			schema: `{"type": "integer", "minimum": 1, "maximum": 100}`,
+			value:  `1000000`,
+			want:   []Violation{{Message: "must be at most 100"}},
+		},
+		{
+			name:   "exclusive bounds",
+			schema: `{"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1}`,
+			value:  `0`,
+			want:   []Violation{{Message: "must be greater than 0"}},
+		},
+		{
+			name:   "type list",
+			schema: `{"type": ["string", "null"]}`,
+			value:  `true`,
+			want:   []Violation{{Message: "must be a string or null"}},
+		},
+		{
+			name:   "enum and const",
+			schema: `{"enum": ["a", "b"], "const": "a"}`,
+			value:  `"c"`,
+			want:   []Violation{{Message: `must be one of "a", "b"`}, {Message: `must be "a"`}},
+		},
+		{
+			name:   "string lengths count characters",
+			schema: `{"type": "string", "minLength": 2, "maxLength": 3}`,
+			value:  `"éé"`,
+		},
+		{
+			name:   "empty string",
+			schema: `{"type": "string", "minLength": 1, "pattern": "\\S"}`,
+			value:  `""`,
+			want:   []Violation{{Message: "must not be empty"}, {Message: `must match the pattern \S`}},
+		},
+		{
+			name:   "nested path",
+			schema: `{"type": "object", "properties": {"series": {"type": "array", "items": {"type": "object", "properties": {"values": {"type": "array", "minItems": 1, "items": {"type": "number"}}}}}}}`,
+			value:  `{"series": [{"values": [1]}, {"values": ["x", 2]}, {"values": []}]}`,
+			want: []Violation{
+				{Field: "series[1].values[0]", Message: "must be a number"},
+				{Field: "series[2].values", Message: "must have at least 1 items"},
+			},
+		},
+		{
+			name:   "nested required",
+			schema: `{"type": "object", "properties": {"a": {"type": "object", "required": ["b"]}}}`,
+			value:  `{"a": {}}`,
+			want:   []Violation{{Field: "a.b", Message: "is required"}},
+		},
+		{
+			name:   "additionalProperties false",
+			schema: `{"type": "object", "properties": {"a": {}}, "additionalProperties": false}`,
+			value:  `{"a": 1, "c": 2, "b": 3}`,
+			want: []Violation{
+				{Field: "b", Message: "is not a known property"},
+				{Field: "c", Message: "is not a known property"},
+			},
+		},
+		{
+			name:   "additionalProperties schema",
+			schema: `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": {"type": "integer"}}`,
+			value:  `{"a": "x", "b": 1, "c": 1.5}`,
+			want:   []Violation{{Field: "c", Message: "must be a whole number"}},
+		},
+		{
+			name:   "propertyNames",
+			schema: `{"type": "object", "propertyNames": {"pattern": "^[a-z]+$", "maxLength": 3}, "additionalProperties": {"type": "number"}}`,
+			value:  `{"ok": 1, "Bad": 2, "long": 3}`,
+			want: []Violation{
+				{Field: "Bad", Message: "is not an allowed name: it must match the pattern ^[a-z]+$"},
+				{Field: "long", Message: "is not an allowed name: it must be at most 3 characters long"},
+			},
+		},
+		{
+			name:   "wrong type stops there",
+			schema: `{"type": "object", "required": ["a"]}`,
+			value:  `[]`,
+			want:   []Violation{{Message: "must be an object"}},
+		},
+		{
+			name:   "invalid pattern ignored",
+			schema: `{"type": "string", "pattern": "("}`,
+			value:  `"x"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := decode(t, tt.schema).(map[string]any)
+			got := Check(schema, decode(t, tt.value))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%s, %s) = %v, want %v", tt.schema, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViolationString(t *testing.T) {
+	if got := (Violation{Field: "a.b", Message: "is required"}).String(); got != "a.b is required" {
+		t.Errorf("got %q", got)
+	}
+	if got := (Violation{Message: "must be an object"}).String(); got != "must be an object" {
+		t.Errorf("got %q", got)
+	}
+}